@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/patraden/code-with-kids/pkg/draw"
+)
+
+// runAnalyze runs many draws of the same teams and reports how fairly
+// opponents are spread
+func runAnalyze(args []string) error {
+	fs := newFlagSet("analyze")
+	input := fs.String("input", "-", "team file, - for stdin")
+	inputFormat := fs.String("input-format", "", fmt.Sprintf("team file format %v, guessed from the file extension when empty", draw.TeamEncodings))
+	output := fs.String("output", "-", "report file, - for stdout")
+	format := fs.String("format", "text", fmt.Sprintf("output format %v", draw.AnalysisEncodings))
+	profile := fs.String("profile", draw.ChampionsLeague.Name, fmt.Sprintf("competition profile %v", draw.Profiles()))
+	draws := fs.Int("draws", draw.DefaultAnalysisDraws, "number of draws to run")
+	workers := fs.Int("workers", 0, "number of parallel workers (all CPUs when 0)")
+	seed := fs.Int64("seed", 0, "seed of the first draw (random when not set)")
+	threshold := fs.Float64("threshold", draw.DefaultThreshold, "z-score beyond which a pairing is flagged")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+
+	if err := oneOf("format", *format, draw.AnalysisEncodings); err != nil {
+		return err
+	}
+	if err := oneOf("profile", *profile, draw.Profiles()); err != nil {
+		return err
+	}
+	if *inputFormat == "" {
+		*inputFormat = draw.Encoding(*input)
+	}
+	if err := oneOf("input-format", *inputFormat, draw.TeamEncodings); err != nil {
+		return err
+	}
+	if *draws <= 0 || *threshold <= 0 {
+		return fmt.Errorf("%w: -draws and -threshold must be positive", errUsage)
+	}
+
+	teams, err := readTeams(*input, *inputFormat)
+	if err != nil {
+		return err
+	}
+
+	config := draw.DefaultAnalysisConfig()
+	config.Format, _ = draw.Profile(*profile)
+	config.Draws = *draws
+	config.Workers = *workers
+	config.Threshold = *threshold
+	if isFlagSet(fs, "seed") {
+		config.Seed = *seed
+	}
+
+	analysis, err := draw.Analyze(teams, config)
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(*output, func(w io.Writer) error {
+		return draw.WriteAnalysis(w, analysis, *format)
+	})
+}
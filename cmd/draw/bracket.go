@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/patraden/code-with-kids/pkg/league"
+)
+
+// runBracket draws the knockout phase from the final league phase standings
+func runBracket(args []string) error {
+	fs := newFlagSet("bracket")
+	in := addStandingsFlags(fs)
+	output := fs.String("output", "-", "bracket file, - for stdout")
+	format := fs.String("format", "text", fmt.Sprintf("output format %v", league.BracketEncodings))
+	seed := fs.Int64("seed", 0, "seed for the knockout draw (random when not set)")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+
+	if err := oneOf("format", *format, league.BracketEncodings); err != nil {
+		return err
+	}
+	if !isFlagSet(fs, "seed") {
+		*seed = time.Now().UnixNano()
+	}
+
+	standings, err := in.load()
+	if err != nil {
+		return err
+	}
+
+	bracket, err := league.NewBracket(standings, *seed)
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(*output, func(w io.Writer) error {
+		return league.WriteBracket(w, bracket, *format)
+	})
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/patraden/code-with-kids/pkg/draw"
+)
+
+// runCeremony presents a draw step by step, pot by pot and team by team
+func runCeremony(args []string) error {
+	fs := newFlagSet("ceremony")
+	input := fs.String("input", "-", "draw file, - for stdin")
+	inputFormat := fs.String("input-format", "", fmt.Sprintf("draw file format %v, guessed from the file extension when empty", draw.Encodings))
+	manifest := fs.String("manifest", "", "replay the draw from this manifest instead of reading -input")
+	output := fs.String("output", "-", "event log file, - for stdout")
+	format := fs.String("format", "text", fmt.Sprintf("output format %v, json writes one event per line", draw.EventEncodings))
+	delay := fs.Duration("delay", 0, "pause between events, e.g. 2s")
+	step := fs.Bool("step", false, "wait for Enter before drawing each team")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+
+	if *inputFormat == "" {
+		*inputFormat = draw.Encoding(*input)
+	}
+	if err := oneOf("input-format", *inputFormat, draw.Encodings); err != nil {
+		return err
+	}
+	if err := oneOf("format", *format, draw.EventEncodings); err != nil {
+		return err
+	}
+	if *delay < 0 {
+		return fmt.Errorf("%w: -delay must not be negative", errUsage)
+	}
+	if *step && *input == "-" && *manifest == "" {
+		return fmt.Errorf("%w: -step reads Enter from stdin, so the draw must come from -input or -manifest", errUsage)
+	}
+
+	d, err := loadDraw(*input, *inputFormat, *manifest)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	return writeOutput(*output, func(w io.Writer) error {
+		enter := bufio.NewScanner(os.Stdin)
+		for e := range draw.NewPlayer(draw.Events(d, nil), *delay).Play(ctx) {
+			if *step && e.Kind == draw.EventTeamDrawn {
+				fmt.Fprint(os.Stderr, "press Enter to draw the next team ")
+				if !enter.Scan() {
+					return nil
+				}
+			}
+			if err := draw.WriteEvent(w, e, *format); err != nil {
+				return err
+			}
+		}
+		return ctx.Err()
+	})
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/patraden/code-with-kids/pkg/draw"
+)
+
+// runCommit generates a server seed, keeps it in a new private file and
+// prints the commitment to publish before the draw
+func runCommit(args []string) error {
+	fs := newFlagSet("commit")
+	secret := fs.String("secret", "secret.txt", "where to keep the server seed until the reveal")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+
+	serverSeed, err := draw.NewServerSeed()
+	if err != nil {
+		return err
+	}
+
+	// Never overwrite a seed whose commitment may already be published
+	file, err := os.OpenFile(*secret, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.WriteString(serverSeed + "\n"); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	fmt.Println(draw.Commit(serverSeed))
+	return nil
+}
+
+// runVerify checks a published manifest against its commitment and replays it
+func runVerify(args []string) error {
+	fs := newFlagSet("verify")
+	manifest := fs.String("manifest", "-", "manifest of the published draw, - for stdin")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+
+	m, err := readManifest(*manifest)
+	if err != nil {
+		return err
+	}
+
+	if _, err := draw.Verify(m); err != nil {
+		return fmt.Errorf("%w: %w", errInvalid, err)
+	}
+
+	fmt.Printf("draw verified: commitment %s, seed %d\n", m.Reveal.Commitment, m.Seed)
+	return nil
+}
+
+// readReveal reads the server seed kept by commit and pairs it with the
+// participant seed
+func readReveal(path, participantSeed string) (*draw.Reveal, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	serverSeed := strings.TrimSpace(string(b))
+	return &draw.Reveal{
+		Commitment:      draw.Commit(serverSeed),
+		ServerSeed:      serverSeed,
+		ParticipantSeed: participantSeed,
+	}, nil
+}
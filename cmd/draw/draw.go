@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/patraden/code-with-kids/pkg/draw"
+)
+
+func runDraw(args []string) error {
+	fs := newFlagSet("draw")
+	input := fs.String("input", "-", "team file, - for stdin")
+	inputFormat := fs.String("input-format", "", fmt.Sprintf("team file format %v, guessed from the file extension when empty", draw.TeamEncodings))
+	output := fs.String("output", "-", "result file, - for stdout")
+	format := fs.String("format", "text", fmt.Sprintf("output format %v", draw.Encodings))
+	profile := fs.String("profile", draw.ChampionsLeague.Name, fmt.Sprintf("competition profile %v", draw.Profiles()))
+	seed := fs.Int64("seed", 0, "seed for the opponent draw (random when not set)")
+	manifest := fs.String("manifest", "", "also write the draw manifest to this file")
+	replay := fs.String("replay", "", "re-run the draw recorded in this manifest instead of reading teams")
+	secret := fs.String("secret", "", "server seed file written by 'draw commit'")
+	participant := fs.String("participant", "", "participant seed mixed with the server seed, required with -secret")
+	schedule := fs.Bool("schedule", false, "also split the fixtures into matchdays")
+	storeDir := fs.String("store", "", fmt.Sprintf("also keep the draw in this store directory, such as %q", defaultStoreDir))
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+
+	if err := oneOf("format", *format, draw.Encodings); err != nil {
+		return err
+	}
+	if err := oneOf("profile", *profile, draw.Profiles()); err != nil {
+		return err
+	}
+	if *inputFormat == "" {
+		*inputFormat = draw.Encoding(*input)
+	}
+	if err := oneOf("input-format", *inputFormat, draw.TeamEncodings); err != nil {
+		return err
+	}
+	if *replay != "" && (isFlagSet(fs, "input") || isFlagSet(fs, "seed") || isFlagSet(fs, "profile") || *secret != "") {
+		return fmt.Errorf("%w: -replay cannot be combined with -input, -seed, -profile or -secret", errUsage)
+	}
+	if isFlagSet(fs, "seed") && *secret != "" {
+		return fmt.Errorf("%w: -seed cannot be combined with -secret", errUsage)
+	}
+	// without a participant seed the operator alone would choose the draw
+	if *secret != "" && *participant == "" {
+		return fmt.Errorf("%w: -secret needs a non-empty -participant seed", errUsage)
+	}
+	if *participant != "" && *secret == "" {
+		return fmt.Errorf("%w: -participant needs -secret", errUsage)
+	}
+
+	var (
+		teams  []draw.Team
+		result *draw.Draw
+		reveal *draw.Reveal
+		err    error
+	)
+
+	if *replay != "" {
+		m, err := readManifest(*replay)
+		if err != nil {
+			return err
+		}
+
+		teams, reveal = m.Teams, m.Reveal
+		if result, err = draw.Replay(m); err != nil {
+			return err
+		}
+	} else {
+		if teams, err = readTeams(*input, *inputFormat); err != nil {
+			return err
+		}
+
+		config := draw.DefaultConfig()
+		config.Format, _ = draw.Profile(*profile)
+		if isFlagSet(fs, "seed") {
+			config.Seed = *seed
+		}
+
+		if *secret != "" {
+			if reveal, err = readReveal(*secret, *participant); err != nil {
+				return err
+			}
+			config.Seed = reveal.Seed()
+		}
+
+		if result, err = draw.NewEngine(config).Run(teams); err != nil {
+			return err
+		}
+	}
+
+	// the manifest checksum covers the draw itself, before scheduling
+	if *manifest != "" {
+		m, err := draw.NewManifest(result, teams)
+		if err != nil {
+			return err
+		}
+		m.Reveal = reveal
+
+		if err := writeOutput(*manifest, func(w io.Writer) error {
+			return draw.WriteManifest(w, m)
+		}); err != nil {
+			return err
+		}
+	}
+
+	if *schedule {
+		if err := draw.Schedule(result); err != nil {
+			return err
+		}
+	}
+
+	if *storeDir != "" {
+		if err := storeDraw(*storeDir, result); err != nil {
+			return err
+		}
+	}
+
+	return writeOutput(*output, func(w io.Writer) error {
+		return draw.Write(w, result, *format)
+	})
+}
+
+func readTeams(path, encoding string) ([]draw.Team, error) {
+	r, err := openInput(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	name := path
+	if path == "-" {
+		name = "<stdin>"
+	}
+
+	return draw.LoadTeams(r, name, encoding)
+}
+
+func readDraw(path, encoding string) (*draw.Draw, error) {
+	r, err := openInput(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return draw.Read(r, encoding)
+}
+
+func readManifest(path string) (*draw.Manifest, error) {
+	r, err := openInput(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return draw.ReadManifest(r)
+}
+
+// oneOf checks that the flag value is one of the allowed values
+func oneOf(name, value string, allowed []string) error {
+	for _, v := range allowed {
+		if v == value {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: unknown %s %q, expected one of %v", errUsage, name, value, allowed)
+}
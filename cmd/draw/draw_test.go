@@ -0,0 +1,32 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunDrawSeedFlags(t *testing.T) {
+	secret := filepath.Join(t.TempDir(), "secret.txt")
+	if err := os.WriteFile(secret, []byte("server seed\n"), 0o600); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	teams := filepath.Join("..", "..", "example", "teams.txt")
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"secret without participant", []string{"draw", "-input", teams, "-secret", secret}},
+		{"secret with empty participant", []string{"draw", "-input", teams, "-secret", secret, "-participant", ""}},
+		{"participant without secret", []string{"draw", "-input", teams, "-participant", "audience"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := run(tt.args); code != exitUsage {
+				t.Errorf("Expected exit code %d, got %d", exitUsage, code)
+			}
+		})
+	}
+}
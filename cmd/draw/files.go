@@ -0,0 +1,45 @@
+package main
+
+import (
+	"io"
+	"os"
+)
+
+// openInput opens the named file for reading, or stdin for "-"
+func openInput(path string) (io.ReadCloser, error) {
+	if path == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(path)
+}
+
+// createOutput creates the named file for writing, or stdout for "-"
+func createOutput(path string) (io.WriteCloser, error) {
+	if path == "-" {
+		return nopWriteCloser{os.Stdout}, nil
+	}
+	return os.Create(path)
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// writeOutput writes to the named file or stdout using fn
+func writeOutput(path string, fn func(w io.Writer) error) error {
+	w, err := createOutput(path)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(w); err != nil {
+		w.Close()
+		return err
+	}
+
+	return w.Close()
+}
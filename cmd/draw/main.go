@@ -1,35 +1,145 @@
+// Command draw runs league phase draws and the tools built around them.
+//
+// Usage:
+//
+//	draw <command> [flags]
+//
+// Input and output flags accept "-" for stdin and stdout, so commands can
+// be chained in shell pipelines.
 package main
 
 import (
-	"log"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/patraden/code-with-kids/pkg/draw"
+)
+
+// Exit codes
+const (
+	exitOK      = 0
+	exitError   = 1
+	exitUsage   = 2
+	exitInvalid = 3
 )
 
+// command is a draw subcommand
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+var commands = []command{
+	{"draw", "run a league phase draw", runDraw},
+	{"commit", "publish a commitment to a secret server seed", runCommit},
+	{"verify", "verify a published commit-reveal draw", runVerify},
+	{"schedule", "split drawn fixtures into matchdays", runSchedule},
+	{"analyze", "check the fairness of opponents over many draws", runAnalyze},
+	{"ceremony", "present a draw step by step", runCeremony},
+	{"simulate", "simulate the league phase", runSimulate},
+	{"standings", "rank teams from match results", runStandings},
+	{"bracket", "draw the knockout phase from the standings", runBracket},
+	{"validate", "check a draw against the format rules", runValidate},
+	{"store", "list, show and delete stored draws", runStore},
+}
+
+// errUsage reports invalid command line arguments
+var errUsage = errors.New("usage error")
+
+// errInvalid reports a draw that failed verification or validation
+var errInvalid = errors.New("invalid draw")
+
 func main() {
-	buckets, err := ReadInput("example/teams.txt")
-	if err != nil {
-		log.Fatalf("input file error: %v", err)
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	if len(args) == 0 {
+		usage()
+		return exitUsage
+	}
+
+	name := args[0]
+	if name == "-h" || name == "-help" || name == "--help" || name == "help" {
+		usage()
+		return exitOK
+	}
+
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return exitCode(cmd.run(args[1:]))
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "draw: unknown command %q\n\n", name)
+	usage()
+	return exitUsage
+}
+
+func exitCode(err error) int {
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return exitOK
+	case err == errUsage:
+		// flag parsing has already reported the problem
+		return exitUsage
+	}
+
+	fmt.Fprintf(os.Stderr, "error: %v\n", err)
+	switch {
+	case errors.Is(err, errUsage):
+		return exitUsage
+	case errors.Is(err, errInvalid), errors.Is(err, draw.ErrInvalidTeams):
+		return exitInvalid
 	}
+	return exitError
+}
 
-	a, b, c, d := buckets[0], buckets[1], buckets[2], buckets[3]
-	bucketPairs := [][2]Bucket{
-		{a, b},
-		{a, c},
-		{a, d},
-		{b, c},
-		{b, d},
-		{c, d},
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: draw <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.summary)
 	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Run 'draw <command> -h' for the flags of a command.")
+}
 
-	matchesFinal := []TeamPair{}
-	matchesFinal = append(matchesFinal, GenerateBucketPairs(a)...)
-	matchesFinal = append(matchesFinal, GenerateBucketPairs(b)...)
-	matchesFinal = append(matchesFinal, GenerateBucketPairs(c)...)
-	matchesFinal = append(matchesFinal, GenerateBucketPairs(d)...)
+// newFlagSet creates a flag set that reports errors instead of exiting
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("draw "+name, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	return fs
+}
 
-	for _, pair := range bucketPairs {
-		matches := GenerateCrossBucketsPairs(pair[0], pair[1])
-		matchesFinal = append(matchesFinal, matches...)
+// parse parses the flags and rejects unexpected positional arguments
+func parse(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errUsage
 	}
 
-	WriteOutput(buckets, matchesFinal, "example/results.txt")
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "%s: unexpected argument %q\n", fs.Name(), fs.Arg(0))
+		fs.Usage()
+		return errUsage
+	}
+
+	return nil
+}
+
+func isFlagSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
 }
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/patraden/code-with-kids/pkg/draw"
+)
+
+// runSchedule reads a draw, or replays it from its manifest, and splits the
+// fixtures into matchdays
+func runSchedule(args []string) error {
+	fs := newFlagSet("schedule")
+	input := fs.String("input", "-", "draw file, - for stdin")
+	inputFormat := fs.String("input-format", "", fmt.Sprintf("draw file format %v, guessed from the file extension when empty", draw.Encodings))
+	manifest := fs.String("manifest", "", "replay the draw from this manifest instead of reading -input")
+	output := fs.String("output", "-", "result file, - for stdout")
+	format := fs.String("format", "text", fmt.Sprintf("output format %v", draw.Encodings))
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+
+	if *inputFormat == "" {
+		*inputFormat = draw.Encoding(*input)
+	}
+	if err := oneOf("input-format", *inputFormat, draw.Encodings); err != nil {
+		return err
+	}
+	if err := oneOf("format", *format, draw.Encodings); err != nil {
+		return err
+	}
+
+	result, err := loadDraw(*input, *inputFormat, *manifest)
+	if err != nil {
+		return err
+	}
+
+	if err := draw.Schedule(result); err != nil {
+		return err
+	}
+
+	return writeOutput(*output, func(w io.Writer) error {
+		return draw.Write(w, result, *format)
+	})
+}
+
+// loadDraw replays the manifest when one is given and reads the draw file
+// otherwise
+func loadDraw(input, encoding, manifest string) (*draw.Draw, error) {
+	if manifest == "" {
+		return readDraw(input, encoding)
+	}
+
+	m, err := readManifest(manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	return draw.Replay(m)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/patraden/code-with-kids/pkg/draw"
+	"github.com/patraden/code-with-kids/pkg/league"
+)
+
+// runSimulate plays the fixtures of a draw many times and reports the
+// finishing chances of every team
+func runSimulate(args []string) error {
+	fs := newFlagSet("simulate")
+	input := fs.String("input", "-", "draw file, - for stdin")
+	inputFormat := fs.String("input-format", "", fmt.Sprintf("draw file format %v, guessed from the file extension when empty", draw.Encodings))
+	manifest := fs.String("manifest", "", "replay the draw from this manifest instead of reading -input")
+	output := fs.String("output", "-", "result file, - for stdout")
+	format := fs.String("format", "text", fmt.Sprintf("output format %v", league.Encodings))
+	runs := fs.Int("runs", league.DefaultRuns, "number of simulated league phases")
+	workers := fs.Int("workers", 0, "number of parallel workers (all CPUs when 0)")
+	seed := fs.Int64("seed", 0, "seed for the simulated results (random when not set)")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+
+	if *inputFormat == "" {
+		*inputFormat = draw.Encoding(*input)
+	}
+	if err := oneOf("input-format", *inputFormat, draw.Encodings); err != nil {
+		return err
+	}
+	if err := oneOf("format", *format, league.Encodings); err != nil {
+		return err
+	}
+	if *runs <= 0 {
+		return fmt.Errorf("%w: -runs must be positive", errUsage)
+	}
+
+	d, err := loadDraw(*input, *inputFormat, *manifest)
+	if err != nil {
+		return err
+	}
+
+	config := league.DefaultConfig()
+	config.Runs = *runs
+	config.Workers = *workers
+	if isFlagSet(fs, "seed") {
+		config.Seed = *seed
+	}
+
+	result, err := league.NewSimulator(config).Run(d)
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(*output, func(w io.Writer) error {
+		return league.Write(w, result, *format)
+	})
+}
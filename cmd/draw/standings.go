@@ -0,0 +1,97 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/patraden/code-with-kids/pkg/draw"
+	"github.com/patraden/code-with-kids/pkg/league"
+)
+
+// runStandings ranks the teams of a draw from the results played so far
+func runStandings(args []string) error {
+	fs := newFlagSet("standings")
+	in := addStandingsFlags(fs)
+	output := fs.String("output", "-", "standings file, - for stdout")
+	format := fs.String("format", "text", fmt.Sprintf("output format %v", league.Encodings))
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+
+	if err := oneOf("format", *format, league.Encodings); err != nil {
+		return err
+	}
+
+	standings, err := in.load()
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(*output, func(w io.Writer) error {
+		return league.WriteStandings(w, standings, *format)
+	})
+}
+
+// standingsInput locates a draw and the results played so far
+type standingsInput struct {
+	input         *string
+	inputFormat   *string
+	manifest      *string
+	results       *string
+	resultsFormat *string
+}
+
+func addStandingsFlags(fs *flag.FlagSet) *standingsInput {
+	return &standingsInput{
+		input:         fs.String("input", "-", "draw file, - for stdin"),
+		inputFormat:   fs.String("input-format", "", fmt.Sprintf("draw file format %v, guessed from the file extension when empty", draw.Encodings)),
+		manifest:      fs.String("manifest", "", "replay the draw from this manifest instead of reading -input"),
+		results:       fs.String("results", "", "match results file (required)"),
+		resultsFormat: fs.String("results-format", "", fmt.Sprintf("match results format %v, guessed from the file extension when empty", league.MatchEncodings)),
+	}
+}
+
+// load reads the draw and the results and builds the standings
+func (in *standingsInput) load() (*league.Standings, error) {
+	if *in.results == "" {
+		return nil, fmt.Errorf("%w: -results is required", errUsage)
+	}
+	if *in.results == "-" && *in.input == "-" && *in.manifest == "" {
+		return nil, fmt.Errorf("%w: -input and -results cannot both read stdin", errUsage)
+	}
+	if *in.inputFormat == "" {
+		*in.inputFormat = draw.Encoding(*in.input)
+	}
+	if err := oneOf("input-format", *in.inputFormat, draw.Encodings); err != nil {
+		return nil, err
+	}
+	if *in.resultsFormat == "" {
+		*in.resultsFormat = draw.Encoding(*in.results)
+	}
+	if err := oneOf("results-format", *in.resultsFormat, league.MatchEncodings); err != nil {
+		return nil, err
+	}
+
+	d, err := loadDraw(*in.input, *in.inputFormat, *in.manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	matches, err := readMatches(*in.results, *in.resultsFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	return league.NewStandings(d, matches)
+}
+
+func readMatches(path, encoding string) ([]league.Match, error) {
+	r, err := openInput(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return league.ReadMatches(r, encoding)
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/patraden/code-with-kids/pkg/draw"
+	"github.com/patraden/code-with-kids/pkg/store"
+)
+
+// defaultStoreDir is where stored draws are kept unless -dir or -store says
+// otherwise
+const defaultStoreDir = "draws"
+
+// storeActions lists the actions of the store command
+var storeActions = []string{"list", "show", "delete", "history"}
+
+// runStore lists, shows and deletes the draws kept with 'draw draw -store'
+func runStore(args []string) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "--help" {
+		fmt.Fprintf(os.Stderr, "Usage: draw store <action> [flags]\n\nActions: %v\n", storeActions)
+		if len(args) == 0 {
+			return errUsage
+		}
+		return flag.ErrHelp
+	}
+
+	action := args[0]
+	if err := oneOf("action", action, storeActions); err != nil {
+		return err
+	}
+
+	fs := newFlagSet("store " + action)
+	dir := fs.String("dir", defaultStoreDir, "directory holding the stored draws")
+	id := fs.String("id", "", "ID of the draw, required by show and delete")
+	output := fs.String("output", "-", "output file, - for stdout")
+	format := fs.String("format", "text", fmt.Sprintf("output format, %v for show and [text json] otherwise", draw.Encodings))
+	if err := parse(fs, args[1:]); err != nil {
+		return err
+	}
+
+	formats := []string{"text", "json"}
+	if action == "show" {
+		formats = draw.Encodings
+	}
+	if err := oneOf("format", *format, formats); err != nil {
+		return err
+	}
+	if *id == "" && (action == "show" || action == "delete") {
+		return fmt.Errorf("%w: %s needs -id", errUsage, action)
+	}
+
+	s, err := store.NewFileStore(*dir)
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case "list":
+		records, err := s.List()
+		if err != nil {
+			return err
+		}
+		return writeOutput(*output, func(w io.Writer) error {
+			return writeRecords(w, records, *format)
+		})
+
+	case "show":
+		r, err := s.Get(*id)
+		if err != nil {
+			return err
+		}
+		return writeOutput(*output, func(w io.Writer) error {
+			return draw.Write(w, r.Draw, *format)
+		})
+
+	case "delete":
+		return s.Delete(*id)
+
+	default:
+		changes, err := s.History(*id)
+		if err != nil {
+			return err
+		}
+		return writeOutput(*output, func(w io.Writer) error {
+			return writeChanges(w, changes, *format)
+		})
+	}
+}
+
+// storeDraw keeps the draw in the store directory and reports its ID
+func storeDraw(dir string, d *draw.Draw) error {
+	s, err := store.NewFileStore(dir)
+	if err != nil {
+		return err
+	}
+
+	r, err := s.Create(d)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "stored draw %s in %s\n", r.ID, dir)
+	return nil
+}
+
+// record is a line of the store listing
+type record struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Format    string    `json:"format"`
+	Seed      int64     `json:"seed"`
+	Results   int       `json:"results"`
+}
+
+func writeRecords(w io.Writer, records []*store.Record, format string) error {
+	lines := make([]record, len(records))
+	for i, r := range records {
+		lines[i] = record{
+			ID:        r.ID,
+			CreatedAt: r.CreatedAt,
+			UpdatedAt: r.UpdatedAt,
+			Format:    r.Draw.Format.Name,
+			Seed:      r.Draw.Seed,
+			Results:   len(r.Results),
+		}
+	}
+
+	if format == "json" {
+		return writeJSON(w, lines)
+	}
+
+	for _, l := range lines {
+		if _, err := fmt.Fprintf(w, "%s  %s  %-6s  seed %d, %d results\n", l.ID, l.CreatedAt.Format(time.DateTime), l.Format, l.Seed, l.Results); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeChanges(w io.Writer, changes []store.Change, format string) error {
+	if format == "json" {
+		return writeJSON(w, changes)
+	}
+
+	for _, c := range changes {
+		if _, err := fmt.Fprintf(w, "%s  %-7s  %s\n", c.Time.Format(time.DateTime), c.Action, c.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
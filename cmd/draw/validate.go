@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/patraden/code-with-kids/pkg/draw"
+)
+
+// runValidate reads a draw, made by draw or by hand, and reports every
+// rule of its format it breaks
+func runValidate(args []string) error {
+	fs := newFlagSet("validate")
+	input := fs.String("input", "-", "draw file, - for stdin")
+	inputFormat := fs.String("input-format", "", fmt.Sprintf("draw file format %v, guessed from the file extension when empty", draw.Encodings))
+	output := fs.String("output", "-", "report file, - for stdout")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+
+	if *inputFormat == "" {
+		*inputFormat = draw.Encoding(*input)
+	}
+	if err := oneOf("input-format", *inputFormat, draw.Encodings); err != nil {
+		return err
+	}
+
+	d, err := readDraw(*input, *inputFormat)
+	if err != nil {
+		return err
+	}
+
+	err = d.Validate()
+	var verr *draw.ValidationError
+	if err != nil && !errors.As(err, &verr) {
+		return err
+	}
+
+	if err := writeOutput(*output, func(w io.Writer) error {
+		return writeReport(w, d, verr)
+	}); err != nil {
+		return err
+	}
+
+	if verr != nil {
+		return fmt.Errorf("%w: %d violations", errInvalid, len(verr.Violations))
+	}
+	return nil
+}
+
+// writeReport lists the violations found in the draw, or confirms that
+// there are none
+func writeReport(w io.Writer, d *draw.Draw, verr *draw.ValidationError) error {
+	if verr == nil {
+		_, err := fmt.Fprintf(w, "valid: %v, %d matches\n", d.Format, len(d.Fixtures))
+		return err
+	}
+
+	for _, v := range verr.Violations {
+		if _, err := fmt.Fprintln(w, v); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "invalid: %v, %d violations\n", d.Format, len(verr.Violations))
+	return err
+}
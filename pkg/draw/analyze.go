@@ -0,0 +1,433 @@
+package draw
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultAnalysisDraws is the number of draws analysed when unset
+	DefaultAnalysisDraws = 1000
+	// DefaultThreshold is the z-score beyond which a pairing is flagged
+	DefaultThreshold = 4.0
+	// minExpected is the expected number of meetings above which a pairing
+	// that never happened is flagged
+	minExpected = 5.0
+)
+
+// Flag reasons reported by Analyze
+const (
+	FlagOverRepresented  = "over-represented"
+	FlagUnderRepresented = "under-represented"
+	FlagNeverDrawn       = "never drawn"
+)
+
+// AnalysisConfig holds configuration for Analyze
+type AnalysisConfig struct {
+	// Seed determines the draws; every draw gets its own seed derived from it
+	Seed int64
+	// Draws is the number of draws to run, DefaultAnalysisDraws when zero
+	Draws int
+	// Workers is the number of draws run in parallel, GOMAXPROCS when zero
+	Workers int
+	// Format and Constraints configure every draw as in Config
+	Format      Format
+	Constraints []Constraint
+	// Threshold is the z-score beyond which a pairing frequency is flagged,
+	// DefaultThreshold when zero
+	Threshold float64
+}
+
+// DefaultAnalysisConfig returns a configuration seeded from the current time
+func DefaultAnalysisConfig() *AnalysisConfig {
+	return &AnalysisConfig{
+		Seed:        time.Now().UnixNano(),
+		Draws:       DefaultAnalysisDraws,
+		Workers:     runtime.GOMAXPROCS(0),
+		Format:      ChampionsLeague,
+		Constraints: DefaultConstraints(),
+		Threshold:   DefaultThreshold,
+	}
+}
+
+// OpponentShare is how often a team met one opponent
+type OpponentShare struct {
+	Team string `json:"team"`
+	// Share is the fraction of draws the two teams met in
+	Share float64 `json:"share"`
+	// Expected is the share under a uniform draw among allowed opponents
+	Expected float64 `json:"expected"`
+}
+
+// TeamAnalysis describes the opponents a team was given over many draws
+type TeamAnalysis struct {
+	Team string `json:"team"`
+	Pot  string `json:"pot"`
+	// Opponents lists every opponent met at least once, most frequent first
+	Opponents []OpponentShare `json:"opponents"`
+	// Strength is the mean coefficient of the team's opponents and
+	// StrengthDeviation its standard deviation across draws
+	Strength          float64 `json:"strength"`
+	StrengthDeviation float64 `json:"strength_deviation"`
+	// Index is Strength relative to the average over all teams, 100 being
+	// an average schedule
+	Index float64 `json:"index"`
+}
+
+// Flag reports a pairing whose frequency is unlikely under a uniform draw
+type Flag struct {
+	Team     string  `json:"team"`
+	Opponent string  `json:"opponent"`
+	Reason   string  `json:"reason"`
+	Share    float64 `json:"share"`
+	Expected float64 `json:"expected"`
+	// Z is the distance from Expected in standard deviations. It is left
+	// out for pairings the constraints rule out, which have none.
+	Z float64 `json:"z,omitempty"`
+}
+
+func (f Flag) String() string {
+	if f.Expected == 0 {
+		return fmt.Sprintf("%s - %s: %s, met in %.1f%% of draws, ruled out by the constraints",
+			f.Team, f.Opponent, f.Reason, 100*f.Share)
+	}
+	return fmt.Sprintf("%s - %s: %s, met in %.1f%% of draws, %.1f%% expected (z = %+.1f)",
+		f.Team, f.Opponent, f.Reason, 100*f.Share, 100*f.Expected, f.Z)
+}
+
+// Analysis is the result of Analyze
+type Analysis struct {
+	Draws  int            `json:"draws"`
+	Seed   int64          `json:"seed"`
+	Format Format         `json:"format"`
+	Teams  []TeamAnalysis `json:"teams"`
+	Flags  []Flag         `json:"flags"`
+}
+
+// analysisSeed derives the seed of a draw with a splitmix64 step, so nearby
+// analysis seeds do not repeat each other's draws
+func analysisSeed(seed int64, i int) int64 {
+	z := uint64(seed) + uint64(i+1)*0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return int64(z ^ (z >> 31))
+}
+
+// Analyze runs many draws of the teams and compares how often every pair
+// of teams met with a uniform draw, in which a team is equally likely to
+// meet each team of a pot it is allowed to play. Pairings whose frequency
+// is further than the threshold in standard deviations from uniform, or
+// which never happened although they were expected several times, are
+// flagged. The baseline ignores limits that depend on earlier opponents,
+// such as CountryLimit, so flags point at pairings worth a closer look
+// rather than proving a bias.
+func Analyze(teams []Team, config *AnalysisConfig) (*Analysis, error) {
+	if config == nil {
+		config = DefaultAnalysisConfig()
+	}
+
+	draws := config.Draws
+	if draws <= 0 {
+		draws = DefaultAnalysisDraws
+	}
+	workers := config.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	threshold := config.Threshold
+	if threshold <= 0 {
+		threshold = DefaultThreshold
+	}
+
+	// the first draw checks the input and fixes the pots, which do not
+	// depend on the seed
+	engine := NewEngine(&Config{Seed: analysisSeed(config.Seed, 0), Format: config.Format, Constraints: config.Constraints})
+	first, err := engine.Run(teams)
+	if err != nil {
+		return nil, err
+	}
+
+	// draws are collected by index and counted in order, so the floating
+	// point sums do not depend on which worker finished first
+	results := make([]*Draw, draws)
+	results[0] = first
+
+	indices := make(chan int)
+	errs := make(chan error, workers)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				d, err := NewEngine(&Config{Seed: analysisSeed(config.Seed, i), Format: config.Format, Constraints: config.Constraints}).Run(teams)
+				if err != nil {
+					errs <- err
+					return
+				}
+				results[i] = d
+			}
+		}()
+	}
+
+	var runErr error
+	for i := 1; i < draws && runErr == nil; i++ {
+		select {
+		case indices <- i:
+		case runErr = <-errs:
+		}
+	}
+	close(indices)
+	wg.Wait()
+	if runErr == nil && len(errs) > 0 {
+		runErr = <-errs
+	}
+	if runErr != nil {
+		return nil, runErr
+	}
+
+	a := newAnalyzer(first, engine.constraints)
+	for _, d := range results {
+		a.add(d)
+	}
+
+	return a.analysis(config.Seed, draws, engine.Format(), threshold), nil
+}
+
+// analyzer counts pairings and opponent strength over many draws
+type analyzer struct {
+	teams    []Team
+	pot      []int
+	potNames []string
+	index    map[string]int
+	// allowed[t][o] reports whether t may meet o at all
+	allowed [][]bool
+	met     [][]int
+	// strength sums the mean opponent coefficient per draw, and its square
+	strength   []float64
+	strengthSq []float64
+	draws      int
+	perPot     int
+}
+
+func newAnalyzer(d *Draw, constraints []Constraint) *analyzer {
+	a := &analyzer{index: make(map[string]int), perPot: d.Format.MatchesPerPot}
+	for p, pot := range d.Pots {
+		a.potNames = append(a.potNames, pot.Name)
+		for _, team := range pot.Teams {
+			a.index[team.Name] = len(a.teams)
+			a.teams = append(a.teams, team)
+			a.pot = append(a.pot, p)
+		}
+	}
+
+	n := len(a.teams)
+	none := opponentMap(nil)
+	a.allowed = make([][]bool, n)
+	a.met = make([][]int, n)
+	for t := range n {
+		a.allowed[t] = make([]bool, n)
+		a.met[t] = make([]int, n)
+		for o := range n {
+			if o == t {
+				continue
+			}
+			a.allowed[t][o] = true
+			for _, c := range constraints {
+				if !c.Allow(a.teams[t], a.teams[o], none) || !c.Allow(a.teams[o], a.teams[t], none) {
+					a.allowed[t][o] = false
+					break
+				}
+			}
+		}
+	}
+	a.strength = make([]float64, n)
+	a.strengthSq = make([]float64, n)
+
+	return a
+}
+
+func (a *analyzer) add(d *Draw) {
+	sum := make([]float64, len(a.teams))
+	games := make([]int, len(a.teams))
+	for _, f := range d.Fixtures {
+		h, aw := a.index[f.Home], a.index[f.Away]
+		a.met[h][aw]++
+		a.met[aw][h]++
+		sum[h] += a.teams[aw].Coefficient
+		sum[aw] += a.teams[h].Coefficient
+		games[h]++
+		games[aw]++
+	}
+
+	for t := range a.teams {
+		if games[t] > 0 {
+			mean := sum[t] / float64(games[t])
+			a.strength[t] += mean
+			a.strengthSq[t] += mean * mean
+		}
+	}
+	a.draws++
+}
+
+func (a *analyzer) analysis(seed int64, draws int, format Format, threshold float64) *Analysis {
+	result := &Analysis{Draws: draws, Seed: seed, Format: format}
+	n := float64(a.draws)
+
+	total := 0.0
+	for t, team := range a.teams {
+		mean := a.strength[t] / n
+		variance := max(0, a.strengthSq[t]/n-mean*mean)
+		ta := TeamAnalysis{
+			Team:              team.Name,
+			Pot:               a.potNames[a.pot[t]],
+			Strength:          mean,
+			StrengthDeviation: math.Sqrt(variance),
+		}
+		total += mean
+
+		for o, other := range a.teams {
+			expected := a.expected(t, o)
+			share := float64(a.met[t][o]) / n
+			if a.met[t][o] > 0 {
+				ta.Opponents = append(ta.Opponents, OpponentShare{Team: other.Name, Share: share, Expected: expected})
+			}
+
+			// report every pair once, from the side listed first
+			if o < t {
+				continue
+			}
+			if flag, ok := a.flag(t, o, share, expected, threshold); ok {
+				result.Flags = append(result.Flags, flag)
+			}
+		}
+
+		sort.SliceStable(ta.Opponents, func(i, j int) bool {
+			return ta.Opponents[i].Share > ta.Opponents[j].Share
+		})
+		result.Teams = append(result.Teams, ta)
+	}
+
+	if average := total / float64(len(a.teams)); average > 0 {
+		for i := range result.Teams {
+			result.Teams[i].Index = 100 * result.Teams[i].Strength / average
+		}
+	}
+
+	// pairings the constraints rule out come first
+	sort.SliceStable(result.Flags, func(i, j int) bool {
+		fi, fj := result.Flags[i], result.Flags[j]
+		if (fi.Expected == 0) != (fj.Expected == 0) {
+			return fi.Expected == 0
+		}
+		return math.Abs(fi.Z) > math.Abs(fj.Z)
+	})
+
+	return result
+}
+
+// expected returns the share of draws t meets o in when t's opponents from
+// o's pot are drawn uniformly among those it is allowed to meet
+func (a *analyzer) expected(t, o int) float64 {
+	if !a.allowed[t][o] {
+		return 0
+	}
+
+	candidates := 0
+	for c := range a.teams {
+		if a.pot[c] == a.pot[o] && a.allowed[t][c] {
+			candidates++
+		}
+	}
+	return min(1, float64(a.perPot)/float64(candidates))
+}
+
+func (a *analyzer) flag(t, o int, share, expected, threshold float64) (Flag, bool) {
+	flag := Flag{Team: a.teams[t].Name, Opponent: a.teams[o].Name, Share: share, Expected: expected}
+	n := float64(a.draws)
+
+	switch {
+	case expected == 0 && share > 0:
+		// a pairing the constraints should rule out
+		flag.Reason = FlagOverRepresented
+		return flag, true
+	case expected == 0 || expected == 1:
+		return flag, false
+	}
+
+	flag.Z = (share - expected) * n / math.Sqrt(n*expected*(1-expected))
+	switch {
+	case share == 0 && n*expected >= minExpected:
+		flag.Reason = FlagNeverDrawn
+	case flag.Z >= threshold:
+		flag.Reason = FlagOverRepresented
+	case flag.Z <= -threshold:
+		flag.Reason = FlagUnderRepresented
+	default:
+		return flag, false
+	}
+
+	return flag, true
+}
+
+// AnalysisEncodings lists the encodings accepted by WriteAnalysis
+var AnalysisEncodings = []string{"text", "json"}
+
+// WriteAnalysis writes the analysis using the named encoding
+func WriteAnalysis(w io.Writer, a *Analysis, encoding string) error {
+	switch encoding {
+	case "text":
+		return WriteAnalysisText(w, a)
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(a)
+	}
+	return fmt.Errorf("%w: %q", ErrEncoding, encoding)
+}
+
+// WriteAnalysisText writes the strength of schedule and most frequent
+// opponent of every team, followed by the flagged pairings
+func WriteAnalysisText(w io.Writer, a *Analysis) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "Draws: %d\n", a.Draws)
+	fmt.Fprintf(bw, "Seed: %d\n", a.Seed)
+	fmt.Fprintf(bw, "Format: %v\n\n", a.Format)
+
+	width := len("Team")
+	for _, t := range a.Teams {
+		width = max(width, len([]rune(t.Team)))
+	}
+
+	fmt.Fprintf(bw, "%-*s %3s %6s %15s  %s\n", width, "Team", "Pot", "Index", "Strength", "Most frequent opponent")
+	for _, t := range a.Teams {
+		pad := width - len([]rune(t.Team))
+		frequent := "-"
+		if len(t.Opponents) > 0 {
+			o := t.Opponents[0]
+			frequent = fmt.Sprintf("%s (%.1f%%)", o.Team, 100*o.Share)
+		}
+		fmt.Fprintf(bw, "%s%*s %3s %6.1f %7.1f ± %5.1f  %s\n", t.Team, pad, "", t.Pot, t.Index, t.Strength, t.StrengthDeviation, frequent)
+	}
+
+	fmt.Fprintln(bw)
+	if len(a.Flags) == 0 {
+		fmt.Fprintln(bw, "No unfair pairings found")
+		return bw.Flush()
+	}
+
+	fmt.Fprintf(bw, "Flagged pairings: %d\n", len(a.Flags))
+	for _, f := range a.Flags {
+		fmt.Fprintln(bw, f)
+	}
+
+	return bw.Flush()
+}
@@ -0,0 +1,132 @@
+package draw
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestAnalyze(t *testing.T) {
+	teams, err := ReadTeamsFile("../../example/teams.txt")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	a, err := Analyze(teams, &AnalysisConfig{Seed: 1, Draws: 40, Workers: 3})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if a.Draws != 40 || len(a.Teams) != 36 {
+		t.Fatalf("Expected 40 draws of 36 teams, got %d and %d", a.Draws, len(a.Teams))
+	}
+
+	total := 0.0
+	for _, team := range a.Teams {
+		shares := 0.0
+		for _, o := range team.Opponents {
+			shares += o.Share
+			if o.Expected == 0 {
+				t.Errorf("Expected %s never to meet %s", team.Team, o.Team)
+			}
+		}
+		// every draw gives each team 8 opponents
+		if math.Abs(shares-8) > 1e-9 {
+			t.Errorf("Expected opponent shares of %s to add up to 8, got %f", team.Team, shares)
+		}
+		total += team.Index
+	}
+
+	if math.Abs(total/36-100) > 1e-9 {
+		t.Errorf("Expected an average index of 100, got %f", total/36)
+	}
+}
+
+func TestAnalyzeDeterministic(t *testing.T) {
+	teams, err := ReadTeamsFile("../../example/teams.txt")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var outputs []string
+	for _, workers := range []int{1, 4} {
+		a, err := Analyze(teams, &AnalysisConfig{Seed: 2, Draws: 10, Workers: workers})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		var buf bytes.Buffer
+		if err := WriteAnalysis(&buf, a, "text"); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		outputs = append(outputs, buf.String())
+	}
+
+	if outputs[0] != outputs[1] {
+		t.Error("Expected the analysis not to depend on the number of workers")
+	}
+	if !strings.Contains(outputs[0], "Draws: 10\n") {
+		t.Errorf("Unexpected report:\n%s", outputs[0])
+	}
+}
+
+func TestAnalysisSeed(t *testing.T) {
+	seen := map[int64]string{}
+	for seed := int64(0); seed < 10; seed++ {
+		for i := range 100 {
+			key := fmt.Sprintf("seed %d draw %d", seed, i)
+			if other, ok := seen[analysisSeed(seed, i)]; ok {
+				t.Fatalf("Expected distinct draw seeds, %s and %s are equal", key, other)
+			}
+			seen[analysisSeed(seed, i)] = key
+		}
+	}
+}
+
+func TestAnalyzerFlags(t *testing.T) {
+	a := &analyzer{
+		teams:   []Team{{Name: "A"}, {Name: "B"}},
+		allowed: [][]bool{{false, true}, {true, false}},
+		draws:   1000,
+	}
+
+	tests := []struct {
+		share, expected float64
+		reason          string
+	}{
+		{0.25, 0.25, ""},
+		{0.40, 0.25, FlagOverRepresented},
+		{0.10, 0.25, FlagUnderRepresented},
+		{0, 0.25, FlagNeverDrawn},
+		{0.01, 0, FlagOverRepresented},
+	}
+
+	for _, tt := range tests {
+		flag, ok := a.flag(0, 1, tt.share, tt.expected, DefaultThreshold)
+		if ok != (tt.reason != "") || flag.Reason != tt.reason {
+			t.Errorf("Expected share %.2f against %.2f to give %q, got %q", tt.share, tt.expected, tt.reason, flag.Reason)
+		}
+	}
+}
+
+func TestWriteAnalysisRuledOut(t *testing.T) {
+	a := &analyzer{
+		teams:   []Team{{Name: "A"}, {Name: "B"}},
+		allowed: [][]bool{{false, false}, {false, false}},
+		draws:   1000,
+	}
+	flag, ok := a.flag(0, 1, 0.01, 0, DefaultThreshold)
+	if !ok {
+		t.Fatal("Expected a pairing the constraints rule out to be flagged")
+	}
+
+	var buf bytes.Buffer
+	if err := WriteAnalysis(&buf, &Analysis{Draws: 1000, Flags: []Flag{flag}}, "json"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if strings.Contains(buf.String(), `"z"`) || !strings.Contains(buf.String(), `"reason": "over-represented"`) {
+		t.Errorf("Unexpected JSON:\n%s", buf.String())
+	}
+}
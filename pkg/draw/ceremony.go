@@ -0,0 +1,337 @@
+package draw
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EventKind identifies what happened at a step of the draw ceremony
+type EventKind string
+
+const (
+	// EventPot presents the teams of a pot
+	EventPot EventKind = "pot"
+	// EventTeamDrawn is a team's ball drawn from its pot
+	EventTeamDrawn EventKind = "team_drawn"
+	// EventConstraint lists the teams a constraint rules out for the team
+	// just drawn
+	EventConstraint EventKind = "constraint"
+	// EventOpponents reveals the opponents of the team just drawn
+	EventOpponents EventKind = "opponents"
+	// EventFinished closes the ceremony
+	EventFinished EventKind = "finished"
+)
+
+// Event is a single step of the draw ceremony
+type Event struct {
+	// Seq numbers the events from 1
+	Seq  int       `json:"seq"`
+	Kind EventKind `json:"kind"`
+	Team string    `json:"team,omitempty"`
+	Pot  string    `json:"pot,omitempty"`
+	// Teams are the teams of the pot, or the teams ruled out by a constraint
+	Teams []string `json:"teams,omitempty"`
+	// Reason describes the constraint
+	Reason string `json:"reason,omitempty"`
+	// Fixtures are the revealed matches of the team, by opponent pot
+	Fixtures []Fixture `json:"fixtures,omitempty"`
+}
+
+func (e Event) String() string {
+	switch e.Kind {
+	case EventPot:
+		return fmt.Sprintf("Pot %s: %s", e.Pot, strings.Join(e.Teams, ", "))
+	case EventTeamDrawn:
+		return fmt.Sprintf("Drawn from pot %s: %s", e.Pot, e.Team)
+	case EventConstraint:
+		return fmt.Sprintf("  %s cannot meet %s (%s)", e.Team, strings.Join(e.Teams, ", "), e.Reason)
+	case EventOpponents:
+		matches := make([]string, len(e.Fixtures))
+		for i, f := range e.Fixtures {
+			if f.Home == e.Team {
+				matches[i] = f.Away + " (home)"
+			} else {
+				matches[i] = f.Home + " (away)"
+			}
+		}
+		return fmt.Sprintf("  %s plays %s", e.Team, strings.Join(matches, ", "))
+	case EventFinished:
+		return "The draw is complete"
+	}
+	return string(e.Kind)
+}
+
+// Events returns the draw as a ceremony: the pots are presented, then the
+// teams are drawn pot by pot, each followed by the teams the constraints
+// rule out given the opponents revealed so far and then by all of its
+// opponents. The order of the balls is seeded by the draw seed, so the
+// same draw always gives the same ceremony and the sequence can be
+// iterated again to replay it. Nil constraints mean DefaultConstraints.
+//
+// The events are rebuilt from the finished draw, not recorded while the
+// engine solved it, so the teams ruled out are those the constraints
+// exclude given the revealed opponents. Country rules need the countries
+// of the teams, which text results do not record; draws read from JSON
+// or CSV show them.
+func Events(d *Draw, constraints []Constraint) iter.Seq[Event] {
+	if constraints == nil {
+		constraints = DefaultConstraints()
+	}
+
+	return func(yield func(Event) bool) {
+		seq := 0
+		emit := func(e Event) bool {
+			seq++
+			e.Seq = seq
+			return yield(e)
+		}
+
+		for _, pot := range d.Pots {
+			names := make([]string, len(pot.Teams))
+			for i, team := range pot.Teams {
+				names[i] = team.Name
+			}
+			if !emit(Event{Kind: EventPot, Pot: pot.Name, Teams: names}) {
+				return
+			}
+		}
+
+		c := newCeremony(d)
+		r := rand.New(rand.NewSource(d.Seed))
+		for _, pot := range d.Pots {
+			balls := append([]Team(nil), pot.Teams...)
+			r.Shuffle(len(balls), func(i, j int) { balls[i], balls[j] = balls[j], balls[i] })
+
+			for _, team := range balls {
+				if !emit(Event{Kind: EventTeamDrawn, Team: team.Name, Pot: pot.Name}) {
+					return
+				}
+				for _, e := range c.excluded(team, constraints) {
+					if !emit(e) {
+						return
+					}
+				}
+				if !emit(Event{Kind: EventOpponents, Team: team.Name, Pot: pot.Name, Fixtures: c.reveal(team)}) {
+					return
+				}
+			}
+		}
+
+		emit(Event{Kind: EventFinished})
+	}
+}
+
+// ceremony tracks the opponents revealed so far
+type ceremony struct {
+	teams    []Team
+	byName   map[string]Team
+	pot      map[string]int
+	fixtures map[string][]Fixture
+	revealed opponentMap
+	shown    map[Fixture]bool
+}
+
+func newCeremony(d *Draw) *ceremony {
+	c := &ceremony{
+		byName:   make(map[string]Team),
+		pot:      make(map[string]int),
+		fixtures: make(map[string][]Fixture),
+		revealed: make(opponentMap),
+		shown:    make(map[Fixture]bool),
+	}
+	for p, pot := range d.Pots {
+		for _, team := range pot.Teams {
+			c.teams = append(c.teams, team)
+			c.byName[team.Name] = team
+			c.pot[team.Name] = p
+		}
+	}
+	for _, f := range d.Fixtures {
+		c.fixtures[f.Home] = append(c.fixtures[f.Home], f)
+		c.fixtures[f.Away] = append(c.fixtures[f.Away], f)
+	}
+	return c
+}
+
+// excluded lists, constraint by constraint, the teams the drawn team may
+// no longer meet
+func (c *ceremony) excluded(team Team, constraints []Constraint) []Event {
+	met := make(map[string]bool)
+	for _, o := range c.revealed[team.Name] {
+		met[o.Name] = true
+	}
+
+	var events []Event
+	for _, constraint := range constraints {
+		var names []string
+		for _, o := range c.teams {
+			if o.Name == team.Name || met[o.Name] {
+				continue
+			}
+			if !constraint.Allow(team, o, c.revealed) || !constraint.Allow(o, team, c.revealed) {
+				names = append(names, o.Name)
+			}
+		}
+		if len(names) > 0 {
+			events = append(events, Event{Kind: EventConstraint, Team: team.Name, Teams: names, Reason: describe(constraint)})
+		}
+	}
+	return events
+}
+
+// reveal returns the fixtures of the team ordered by opponent pot, home
+// game first, and records them as revealed
+func (c *ceremony) reveal(team Team) []Fixture {
+	fixtures := append([]Fixture(nil), c.fixtures[team.Name]...)
+	opponent := func(f Fixture) string {
+		if f.Home == team.Name {
+			return f.Away
+		}
+		return f.Home
+	}
+	sort.SliceStable(fixtures, func(i, j int) bool {
+		pi, pj := c.pot[opponent(fixtures[i])], c.pot[opponent(fixtures[j])]
+		if pi != pj {
+			return pi < pj
+		}
+		return fixtures[i].Home == team.Name && fixtures[j].Home != team.Name
+	})
+
+	for _, f := range fixtures {
+		if c.shown[f] {
+			continue
+		}
+		c.shown[f] = true
+		c.revealed[f.Home] = append(c.revealed[f.Home], c.byName[f.Away])
+		c.revealed[f.Away] = append(c.revealed[f.Away], c.byName[f.Home])
+	}
+
+	return fixtures
+}
+
+// describe names a constraint for the audience
+func describe(c Constraint) string {
+	if s, ok := c.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprintf("%T", c)
+}
+
+// EventEncodings lists the encodings accepted by WriteEvent
+var EventEncodings = []string{"text", "json"}
+
+// WriteEvent writes a single event using the named encoding. JSON events are
+// written one per line so they can be streamed to other tools.
+func WriteEvent(w io.Writer, e Event, encoding string) error {
+	switch encoding {
+	case "text":
+		_, err := fmt.Fprintln(w, e)
+		return err
+	case "json":
+		return json.NewEncoder(w).Encode(e)
+	}
+	return fmt.Errorf("%w: %q", ErrEncoding, encoding)
+}
+
+// Player paces ceremony events for a live presentation. It can be paused
+// and resumed while playing.
+type Player struct {
+	events iter.Seq[Event]
+	delay  time.Duration
+
+	mu   sync.Mutex
+	gate chan struct{} // closed on resume, nil while playing
+}
+
+// NewPlayer creates a player that waits delay before every event
+func NewPlayer(events iter.Seq[Event], delay time.Duration) *Player {
+	return &Player{events: events, delay: delay}
+}
+
+// Play sends the events on the returned channel. The channel is closed
+// after the last event or once ctx is done.
+func (p *Player) Play(ctx context.Context) <-chan Event {
+	out := make(chan Event)
+
+	go func() {
+		defer close(out)
+		for e := range p.events {
+			if !p.wait(ctx) {
+				return
+			}
+			select {
+			case out <- e:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// Pause holds back further events until Resume is called
+func (p *Player) Pause() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.gate == nil {
+		p.gate = make(chan struct{})
+	}
+}
+
+// Resume continues a paused ceremony
+func (p *Player) Resume() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.gate != nil {
+		close(p.gate)
+		p.gate = nil
+	}
+}
+
+// Paused reports whether the player is paused
+func (p *Player) Paused() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.gate != nil
+}
+
+// wait sleeps for the delay and while paused, reporting false once ctx is
+// done
+func (p *Player) wait(ctx context.Context) bool {
+	if p.delay > 0 {
+		timer := time.NewTimer(p.delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	for {
+		p.mu.Lock()
+		gate := p.gate
+		p.mu.Unlock()
+		if gate == nil {
+			return ctx.Err() == nil
+		}
+
+		select {
+		case <-gate:
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package draw
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestEvents(t *testing.T) {
+	teams, err := ReadTeamsFile("../../example/teams.txt")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	d, err := NewEngine(&Config{Seed: 4}).Run(teams)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	events := slices.Collect(Events(d, nil))
+	if !reflect.DeepEqual(events, slices.Collect(Events(d, nil))) {
+		t.Fatal("Expected replaying the ceremony to give the same events")
+	}
+
+	counts := make(map[EventKind]int)
+	revealed := make(map[Fixture]bool)
+	drawn := ""
+	for i, e := range events {
+		if e.Seq != i+1 {
+			t.Fatalf("Expected event %d to have seq %d, got %d", i, i+1, e.Seq)
+		}
+		counts[e.Kind]++
+
+		switch e.Kind {
+		case EventTeamDrawn:
+			drawn = e.Team
+		case EventConstraint, EventOpponents:
+			if e.Team != drawn {
+				t.Errorf("Expected %s event for %s, got %s", e.Kind, drawn, e.Team)
+			}
+		}
+
+		if e.Kind == EventOpponents {
+			if len(e.Fixtures) != 8 {
+				t.Errorf("Expected 8 opponents for %s, got %d", e.Team, len(e.Fixtures))
+			}
+			for _, f := range e.Fixtures {
+				revealed[f] = true
+			}
+		}
+	}
+
+	if counts[EventPot] != 4 || counts[EventTeamDrawn] != 36 || counts[EventOpponents] != 36 || counts[EventFinished] != 1 {
+		t.Errorf("Unexpected event counts %v", counts)
+	}
+	if counts[EventConstraint] == 0 {
+		t.Error("Expected constraint events")
+	}
+	if len(revealed) != len(d.Fixtures) {
+		t.Errorf("Expected all %d fixtures revealed, got %d", len(d.Fixtures), len(revealed))
+	}
+	if events[len(events)-1].Kind != EventFinished {
+		t.Error("Expected the ceremony to end with EventFinished")
+	}
+}
+
+func TestPlayer(t *testing.T) {
+	d := &Draw{Pots: []Pot{{Name: "A", Teams: []Team{{Name: "X"}, {Name: "Y"}}}}}
+	p := NewPlayer(Events(d, nil), 0)
+
+	p.Pause()
+	ch := p.Play(context.Background())
+
+	select {
+	case e := <-ch:
+		t.Fatalf("Expected no event while paused, got %v", e)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	p.Resume()
+	var kinds []EventKind
+	for e := range ch {
+		kinds = append(kinds, e.Kind)
+	}
+
+	want := []EventKind{EventPot, EventTeamDrawn, EventOpponents, EventTeamDrawn, EventOpponents, EventFinished}
+	if !reflect.DeepEqual(kinds, want) {
+		t.Errorf("Expected %v, got %v", want, kinds)
+	}
+}
+
+func TestPlayerCancel(t *testing.T) {
+	d := &Draw{Pots: []Pot{{Name: "A", Teams: []Team{{Name: "X"}, {Name: "Y"}}}}}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ch := NewPlayer(Events(d, nil), time.Hour).Play(ctx)
+	cancel()
+
+	if _, ok := <-ch; ok {
+		t.Error("Expected the channel to close once the context is cancelled")
+	}
+}
@@ -0,0 +1,78 @@
+package draw
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+)
+
+// serverSeedSize is the number of random bytes in a server seed
+const serverSeedSize = 32
+
+// Reveal holds the data published after a commit-reveal draw. Anyone can
+// check that the server seed matches the commitment published before the
+// draw and that the draw seed was derived from both seeds.
+type Reveal struct {
+	Commitment      string `json:"commitment"`
+	ServerSeed      string `json:"server_seed"`
+	ParticipantSeed string `json:"participant_seed"`
+}
+
+// NewServerSeed returns a hex encoded random server seed
+func NewServerSeed() (string, error) {
+	b := make([]byte, serverSeedSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Commit returns the commitment published before the draw: the hex encoded
+// SHA-256 of the server seed text, so it can be checked with sha256sum
+func Commit(serverSeed string) string {
+	sum := sha256.Sum256([]byte(serverSeed))
+	return hex.EncodeToString(sum[:])
+}
+
+// DeriveSeed mixes the server and participant seeds into the draw seed
+func DeriveSeed(serverSeed, participantSeed string) int64 {
+	sum := sha256.Sum256([]byte(serverSeed + ":" + participantSeed))
+	return int64(binary.BigEndian.Uint64(sum[:8]))
+}
+
+// Seed returns the draw seed derived from the revealed seeds
+func (r *Reveal) Seed() int64 {
+	return DeriveSeed(r.ServerSeed, r.ParticipantSeed)
+}
+
+// Verify checks that the revealed server seed matches the commitment and
+// that a participant seed was mixed in
+func (r *Reveal) Verify() error {
+	if r.ParticipantSeed == "" {
+		return ErrNoParticipantSeed
+	}
+	if got := Commit(r.ServerSeed); got != r.Commitment {
+		return fmt.Errorf("%w: commitment is %s, server seed hashes to %s", ErrCommitmentMismatch, r.Commitment, got)
+	}
+	return nil
+}
+
+// Verify checks a published draw: the reveal must match its commitment and
+// seed, and replaying the manifest must give the recorded draw
+func Verify(m *Manifest) (*Draw, error) {
+	if m.Reveal == nil {
+		return nil, fmt.Errorf("%w: manifest has no reveal", ErrCommitmentMismatch)
+	}
+
+	if err := m.Reveal.Verify(); err != nil {
+		return nil, err
+	}
+
+	if seed := m.Reveal.Seed(); seed != m.Seed {
+		return nil, fmt.Errorf("%w: manifest has %d, reveal derives %d", ErrSeedMismatch, m.Seed, seed)
+	}
+
+	return Replay(m)
+}
@@ -0,0 +1,77 @@
+package draw
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	teams, err := ReadTeamsFile("../../example/teams.txt")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	serverSeed, err := NewServerSeed()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	reveal := &Reveal{
+		Commitment:      Commit(serverSeed),
+		ServerSeed:      serverSeed,
+		ParticipantSeed: "kids club 2026",
+	}
+
+	d, err := NewEngine(&Config{Seed: reveal.Seed()}).Run(teams)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	m, err := NewManifest(d, teams)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	m.Reveal = reveal
+
+	if _, err := Verify(m); err != nil {
+		t.Fatalf("Expected draw to verify, got %v", err)
+	}
+
+	// a re-rolled server seed no longer matches the commitment
+	other, _ := NewServerSeed()
+	m.Reveal = &Reveal{Commitment: reveal.Commitment, ServerSeed: other, ParticipantSeed: reveal.ParticipantSeed}
+	if _, err := Verify(m); !errors.Is(err, ErrCommitmentMismatch) {
+		t.Errorf("Expected ErrCommitmentMismatch, got %v", err)
+	}
+
+	// a seed not derived from the reveal is rejected
+	m.Reveal = reveal
+	m.Seed++
+	if _, err := Verify(m); !errors.Is(err, ErrSeedMismatch) {
+		t.Errorf("Expected ErrSeedMismatch, got %v", err)
+	}
+
+	// without a participant seed the operator chose the draw seed alone
+	m.Reveal = &Reveal{Commitment: reveal.Commitment, ServerSeed: reveal.ServerSeed}
+	m.Seed = m.Reveal.Seed()
+	if _, err := Verify(m); !errors.Is(err, ErrNoParticipantSeed) {
+		t.Errorf("Expected ErrNoParticipantSeed, got %v", err)
+	}
+	m.Reveal = reveal
+	m.Seed = reveal.Seed() + 1
+
+	// a manifest without checksum proves nothing about the published draw
+	m.Seed--
+	m.Checksum = ""
+	if _, err := Verify(m); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("Expected ErrChecksumMismatch, got %v", err)
+	}
+}
+
+func TestCommit(t *testing.T) {
+	// echo -n abc | sha256sum
+	want := "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"
+	if got := Commit("abc"); got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+}
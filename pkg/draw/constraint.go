@@ -0,0 +1,156 @@
+package draw
+
+import "fmt"
+
+// Opponents gives constraints access to the fixtures assigned so far
+type Opponents interface {
+	// Opponents returns the teams already drawn against the named team
+	Opponents(team string) []Team
+}
+
+// opponentMap lists opponents by team name; a nil map is a draw that has
+// not started
+type opponentMap map[string][]Team
+
+func (m opponentMap) Opponents(name string) []Team {
+	return m[name]
+}
+
+// Constraint restricts which fixtures the solver may pick
+type Constraint interface {
+	// Allow reports whether home may host away given the fixtures assigned so far
+	Allow(home, away Team, drawn Opponents) bool
+}
+
+// Checker is implemented by constraints that can reject a team list before
+// the draw starts
+type Checker interface {
+	Check(teams []Team, format Format) error
+}
+
+// PotChecker is implemented by constraints that can reject drawn pots for
+// which no valid set of fixtures exists
+type PotChecker interface {
+	CheckPots(pots []Pot) error
+}
+
+// ConstraintFunc adapts an ordinary function to the Constraint interface
+type ConstraintFunc func(home, away Team, drawn Opponents) bool
+
+// Allow calls f(home, away, drawn)
+func (f ConstraintFunc) Allow(home, away Team, drawn Opponents) bool {
+	return f(home, away, drawn)
+}
+
+// DefaultConstraints returns the constraints applied when none are configured
+func DefaultConstraints() []Constraint {
+	return []Constraint{
+		SameCountry{},
+		CountryLimit{Max: MaxOpponentsPerCountry},
+	}
+}
+
+// SameCountry forbids fixtures between teams of the same country
+type SameCountry struct{}
+
+// Allow reports whether the teams come from different countries
+func (SameCountry) Allow(home, away Team, _ Opponents) bool {
+	return home.Country == "" || home.Country != away.Country
+}
+
+func (SameCountry) String() string {
+	return "teams from the same country cannot meet"
+}
+
+// CheckPots rejects pots where the teams of one country outnumber the
+// opponents another pot can offer them
+func (SameCountry) CheckPots(pots []Pot) error {
+	for _, from := range pots {
+		for country, n := range countryCounts(from.Teams) {
+			for _, to := range pots {
+				if free := len(to.Teams) - countryCounts(to.Teams)[country]; n > free {
+					return &ConstraintError{
+						Country: country,
+						Reason: fmt.Sprintf("%d teams in pot %s but only %d possible opponents in pot %s",
+							n, from.Name, free, to.Name),
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func countryCounts(teams []Team) map[string]int {
+	counts := make(map[string]int)
+	for _, team := range teams {
+		if team.Country != "" {
+			counts[team.Country]++
+		}
+	}
+	return counts
+}
+
+// CountryLimit caps the number of opponents a team faces from any single
+// foreign association
+type CountryLimit struct {
+	Max int
+}
+
+// Allow reports whether both teams stay within the limit after the fixture
+func (c CountryLimit) Allow(home, away Team, drawn Opponents) bool {
+	return c.below(home, away.Country, drawn) && c.below(away, home.Country, drawn)
+}
+
+func (c CountryLimit) String() string {
+	return fmt.Sprintf("at most %d opponents from one country", c.Max)
+}
+
+func (c CountryLimit) below(team Team, country string, drawn Opponents) bool {
+	if country == "" {
+		return true
+	}
+
+	count := 0
+	for _, opponent := range drawn.Opponents(team.Name) {
+		if opponent.Country == country {
+			count++
+		}
+	}
+
+	return count < c.Max
+}
+
+// Check rejects team lists where some team cannot find enough opponents
+// within the limit, whatever the pots look like
+func (c CountryLimit) Check(teams []Team, format Format) error {
+	perCountry := make(map[string]int)
+	unconstrained := 0
+	for _, team := range teams {
+		if team.Country == "" {
+			unconstrained++
+			continue
+		}
+		perCountry[team.Country]++
+	}
+
+	matches := format.MatchesPerTeam()
+	for country := range perCountry {
+		available := unconstrained
+		for other, n := range perCountry {
+			if other != country {
+				available += min(n, c.Max)
+			}
+		}
+
+		if available < matches {
+			return &ConstraintError{
+				Country: country,
+				Reason:  fmt.Sprintf("teams can face at most %d opponents, need %d", available, matches),
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+// Package draw implements the league phase draw used by the code-with-kids
+// football tournaments: teams are split into pots and every team is given
+// opponents from each pot.
+package draw
+
+import "fmt"
+
+const (
+	// MaxOpponentsPerCountry is the maximum number of opponents a team may
+	// face from any single foreign association
+	MaxOpponentsPerCountry = 2
+	// AlgorithmVersion identifies the draw algorithm. It changes whenever the
+	// same seed and teams could produce a different draw or different text
+	// output, as manifests record a checksum of the text output.
+	AlgorithmVersion = "solver-2"
+)
+
+// Team represents a club taking part in the draw
+type Team struct {
+	Name string `json:"name"`
+	// Code is the club's short name, such as "RMA"
+	Code string `json:"code,omitempty"`
+	// Country is the association the club belongs to. Teams from the same
+	// country never meet in the league phase. Empty means unconstrained.
+	Country string `json:"country,omitempty"`
+	City    string `json:"city,omitempty"`
+	Stadium string `json:"stadium,omitempty"`
+	// Coefficient is the club's UEFA coefficient used to rank teams into pots
+	Coefficient float64 `json:"coefficient,omitempty"`
+	// Pot optionally places the team in the named pot regardless of ranking
+	Pot string `json:"pot,omitempty"`
+}
+
+// Pot is a group of teams drawn together
+type Pot struct {
+	Name  string `json:"name"`
+	Teams []Team `json:"teams"`
+}
+
+// Fixture is a single match between two teams played at the home team's
+// stadium
+type Fixture struct {
+	Home string `json:"home"`
+	Away string `json:"away"`
+	// Matchday is the round the match is played in, 0 until scheduled
+	Matchday int `json:"matchday,omitempty"`
+}
+
+func (f Fixture) String() string {
+	return fmt.Sprintf("%s - %s", f.Home, f.Away)
+}
+
+// Balance counts the home and away games of a team
+type Balance struct {
+	Team string `json:"team"`
+	Home int    `json:"home"`
+	Away int    `json:"away"`
+}
+
+// Draw holds the result of a league phase draw
+type Draw struct {
+	Seed     int64
+	Version  string
+	Format   Format
+	Pots     []Pot
+	Fixtures []Fixture
+}
+
+// Clone returns a deep copy of the draw
+func (d *Draw) Clone() *Draw {
+	if d == nil {
+		return nil
+	}
+
+	c := *d
+	c.Pots = make([]Pot, len(d.Pots))
+	for i, pot := range d.Pots {
+		c.Pots[i] = Pot{Name: pot.Name, Teams: append([]Team(nil), pot.Teams...)}
+	}
+	c.Fixtures = append([]Fixture(nil), d.Fixtures...)
+	return &c
+}
+
+// PotName returns the letter used for the pot with the given index
+func PotName(i int) string {
+	return string(rune('A' + i))
+}
+
+// Scheduled reports whether every fixture has been given a matchday
+func (d *Draw) Scheduled() bool {
+	for _, f := range d.Fixtures {
+		if f.Matchday == 0 {
+			return false
+		}
+	}
+	return len(d.Fixtures) > 0
+}
+
+// Matchdays groups the fixtures by matchday, in fixture order within each
+// matchday. It returns nil until the draw is scheduled.
+func (d *Draw) Matchdays() [][]Fixture {
+	if !d.Scheduled() {
+		return nil
+	}
+
+	var matchdays [][]Fixture
+	for _, f := range d.Fixtures {
+		for len(matchdays) < f.Matchday {
+			matchdays = append(matchdays, nil)
+		}
+		matchdays[f.Matchday-1] = append(matchdays[f.Matchday-1], f)
+	}
+	return matchdays
+}
+
+// Balances returns the number of home and away games of every team, in pot
+// order
+func (d *Draw) Balances() []Balance {
+	var balances []Balance
+	index := make(map[string]int)
+	for _, pot := range d.Pots {
+		for _, team := range pot.Teams {
+			index[team.Name] = len(balances)
+			balances = append(balances, Balance{Team: team.Name})
+		}
+	}
+
+	for _, f := range d.Fixtures {
+		if i, ok := index[f.Home]; ok {
+			balances[i].Home++
+		}
+		if i, ok := index[f.Away]; ok {
+			balances[i].Away++
+		}
+	}
+
+	return balances
+}
@@ -0,0 +1,284 @@
+package draw
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func testTeams(n int) []Team {
+	teams := make([]Team, n)
+	for i := range teams {
+		teams[i] = Team{Name: fmt.Sprintf("Team %02d", i+1)}
+	}
+	return teams
+}
+
+func TestRunTeamCount(t *testing.T) {
+	_, err := NewEngine(&Config{Seed: 1}).Run(testTeams(35))
+	if !errors.Is(err, ErrTeamCount) {
+		t.Fatalf("Expected ErrTeamCount, got %v", err)
+	}
+
+	var countErr *TeamCountError
+	if !errors.As(err, &countErr) || countErr.Got != 35 {
+		t.Errorf("Expected TeamCountError with Got 35, got %v", err)
+	}
+}
+
+func TestRun(t *testing.T) {
+	for _, name := range Profiles() {
+		format, _ := Profile(name)
+		t.Run(name, func(t *testing.T) {
+			d, err := NewEngine(&Config{Seed: 1, Format: format}).Run(testTeams(format.Teams))
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if len(d.Pots) != format.Pots {
+				t.Fatalf("Expected %d pots, got %d", format.Pots, len(d.Pots))
+			}
+
+			potOf := make(map[string]int)
+			for i, pot := range d.Pots {
+				if len(pot.Teams) != format.PotSize() {
+					t.Errorf("Expected pot %s to have %d teams, got %d", pot.Name, format.PotSize(), len(pot.Teams))
+				}
+				for _, team := range pot.Teams {
+					potOf[team.Name] = i
+				}
+			}
+
+			if len(potOf) != format.Teams {
+				t.Fatalf("Expected %d distinct teams in pots, got %d", format.Teams, len(potOf))
+			}
+
+			if len(d.Fixtures) != format.Matches() {
+				t.Errorf("Expected %d fixtures, got %d", format.Matches(), len(d.Fixtures))
+			}
+
+			checkFixtures(t, d)
+		})
+	}
+}
+
+func TestRunDuplicateTeams(t *testing.T) {
+	teams := testTeams(ChampionsLeague.Teams)
+	teams[5].Name = teams[0].Name
+	if _, err := NewEngine(&Config{Seed: 1}).Run(teams); !errors.Is(err, ErrDuplicateTeam) {
+		t.Fatalf("Expected ErrDuplicateTeam, got %v", err)
+	}
+}
+
+func TestRunInvalidFormat(t *testing.T) {
+	formats := []Format{
+		{Name: "odd", Teams: 30, Pots: 6, MatchesPerPot: 1},
+		// three matches per team cannot be split into home and away pairs
+		{Name: "three", Teams: 12, Pots: 3, MatchesPerPot: 1},
+	}
+	for _, format := range formats {
+		_, err := NewEngine(&Config{Seed: 1, Format: format}).Run(testTeams(format.Teams))
+		if !errors.Is(err, ErrInvalidFormat) {
+			t.Errorf("Expected ErrInvalidFormat for %s, got %v", format.Name, err)
+		}
+	}
+}
+
+// checkFixtures verifies that every team gets the format's opponents from
+// each pot with balanced venues, never meets an opponent twice and
+// respects the country rules
+func checkFixtures(t *testing.T, d *Draw) {
+	t.Helper()
+
+	teams := make(map[string]Team)
+	potOf := make(map[string]int)
+	for i, pot := range d.Pots {
+		for _, team := range pot.Teams {
+			teams[team.Name] = team
+			potOf[team.Name] = i
+		}
+	}
+
+	home := make(map[string][]int)
+	away := make(map[string][]int)
+	met := make(map[[2]string]bool)
+	perCountry := make(map[[2]string]int)
+
+	for _, f := range d.Fixtures {
+		if f.Home == f.Away {
+			t.Errorf("Team %s plays itself", f.Home)
+		}
+
+		key := [2]string{min(f.Home, f.Away), max(f.Home, f.Away)}
+		if met[key] {
+			t.Errorf("Teams %s and %s meet twice", f.Home, f.Away)
+		}
+		met[key] = true
+
+		if home[f.Home] == nil {
+			home[f.Home] = make([]int, len(d.Pots))
+		}
+		if away[f.Away] == nil {
+			away[f.Away] = make([]int, len(d.Pots))
+		}
+		home[f.Home][potOf[f.Away]]++
+		away[f.Away][potOf[f.Home]]++
+
+		a, b := teams[f.Home], teams[f.Away]
+		if a.Country != "" && a.Country == b.Country {
+			t.Errorf("Teams %s and %s are both from %s", f.Home, f.Away, a.Country)
+		}
+		perCountry[[2]string{f.Home, b.Country}]++
+		perCountry[[2]string{f.Away, a.Country}]++
+	}
+
+	for name := range teams {
+		if home[name] == nil {
+			home[name] = make([]int, len(d.Pots))
+		}
+		if away[name] == nil {
+			away[name] = make([]int, len(d.Pots))
+		}
+
+		homeGames := 0
+		for pot := range d.Pots {
+			homeGames += home[name][pot]
+			if d.Format.MatchesPerPot == 1 {
+				if home[name][pot]+away[name][pot] != 1 {
+					t.Errorf("Expected %s to play one team from pot %s", name, PotName(pot))
+				}
+				continue
+			}
+
+			if home[name][pot] != 1 {
+				t.Errorf("Expected %s to host one team from pot %s", name, PotName(pot))
+			}
+			if away[name][pot] != 1 {
+				t.Errorf("Expected %s to visit one team from pot %s", name, PotName(pot))
+			}
+		}
+
+		if diff := 2*homeGames - d.Format.MatchesPerTeam(); diff < -1 || diff > 1 {
+			t.Errorf("Expected %s to have balanced venues, got %d home games", name, homeGames)
+		}
+	}
+
+	for key, n := range perCountry {
+		if key[1] != "" && n > MaxOpponentsPerCountry {
+			t.Errorf("Team %s faces %d opponents from %s", key[0], n, key[1])
+		}
+	}
+}
+
+func TestRunSeed(t *testing.T) {
+	a, _ := NewEngine(&Config{Seed: 42}).Run(testTeams(ChampionsLeague.Teams))
+	b, _ := NewEngine(&Config{Seed: 42}).Run(testTeams(ChampionsLeague.Teams))
+
+	var bufA, bufB bytes.Buffer
+	if err := WriteText(&bufA, a); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	WriteText(&bufB, b)
+
+	if bufA.String() != bufB.String() {
+		t.Error("Expected draws with the same seed to be equal")
+	}
+}
+
+func TestReadTeams(t *testing.T) {
+	teams, err := ReadTeams(strings.NewReader("Real Madrid, ESP\n\nBarcelona\n"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(teams) != 2 || teams[0].Name != "Real Madrid" || teams[1].Name != "Barcelona" {
+		t.Fatalf("Unexpected teams: %v", teams)
+	}
+
+	if teams[0].Country != "ESP" || teams[1].Country != "" {
+		t.Errorf("Unexpected teams: %v", teams)
+	}
+}
+
+func TestRunCountries(t *testing.T) {
+	teams, err := ReadTeamsFile("../../example/teams.txt")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for seed := range int64(5) {
+		d, err := NewEngine(&Config{Seed: seed}).Run(teams)
+		if err != nil {
+			t.Fatalf("Expected no error with seed %d, got %v", seed, err)
+		}
+
+		checkFixtures(t, d)
+	}
+}
+
+func TestRunUnsatisfiable(t *testing.T) {
+	teams := testTeams(ChampionsLeague.Teams)
+	for i := range teams {
+		// three associations can provide at most six opponents
+		teams[i].Country = fmt.Sprintf("C%d", i%3)
+	}
+
+	_, err := NewEngine(&Config{Seed: 1}).Run(teams)
+	if !errors.Is(err, ErrUnsatisfiable) {
+		t.Fatalf("Expected ErrUnsatisfiable, got %v", err)
+	}
+}
+
+func TestRunCustomConstraint(t *testing.T) {
+	// keep the first two teams apart
+	apart := ConstraintFunc(func(home, away Team, _ Opponents) bool {
+		return !(home.Name == "Team 01" && away.Name == "Team 02") &&
+			!(home.Name == "Team 02" && away.Name == "Team 01")
+	})
+
+	config := &Config{Seed: 3, Constraints: []Constraint{apart}}
+	for range 5 {
+		d, err := NewEngine(config).Run(testTeams(ChampionsLeague.Teams))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		for _, f := range d.Fixtures {
+			if (f.Home == "Team 01" && f.Away == "Team 02") || (f.Home == "Team 02" && f.Away == "Team 01") {
+				t.Errorf("Expected constraint to keep %s and %s apart", f.Home, f.Away)
+			}
+		}
+		config.Seed++
+	}
+}
+
+func TestBalances(t *testing.T) {
+	teams, err := ReadTeamsFile("../../example/teams.txt")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for _, format := range []Format{ChampionsLeague, ConferenceLeague} {
+		d, err := NewEngine(&Config{Seed: 9, Format: format}).Run(teams)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		balances := d.Balances()
+		if len(balances) != format.Teams {
+			t.Fatalf("Expected %d balances, got %d", format.Teams, len(balances))
+		}
+		if balances[0].Team != d.Pots[0].Teams[0].Name {
+			t.Errorf("Expected balances in pot order, got %s first", balances[0].Team)
+		}
+
+		half := format.MatchesPerTeam() / 2
+		for _, b := range balances {
+			if b.Home != half || b.Away != half {
+				t.Errorf("Expected %s to play %d home and %d away games, got %d and %d", b.Team, half, half, b.Home, b.Away)
+			}
+		}
+	}
+}
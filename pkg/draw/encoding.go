@@ -0,0 +1,255 @@
+package draw
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// SchemaVersion is the version of the JSON and CSV result formats. It
+// changes whenever a reader of the previous version could misread a file.
+// Version 2 added the home and away balance of every team.
+const SchemaVersion = 2
+
+// minSchemaVersion is the oldest schema version that can still be read
+const minSchemaVersion = 1
+
+// Encodings lists the result encodings accepted by Write and Read
+var Encodings = []string{"text", "json", "csv"}
+
+// Encoding guesses the encoding of a result or team file from its
+// extension, falling back to text
+func Encoding(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return "csv"
+	case ".json":
+		return "json"
+	}
+	return "text"
+}
+
+// Write writes the draw using the named encoding
+func Write(w io.Writer, d *Draw, encoding string) error {
+	switch encoding {
+	case "text":
+		return WriteText(w, d)
+	case "json":
+		return WriteJSON(w, d)
+	case "csv":
+		return WriteCSV(w, d)
+	}
+	return fmt.Errorf("%w: %q", ErrEncoding, encoding)
+}
+
+// Read reads a draw written with the named encoding
+func Read(r io.Reader, encoding string) (*Draw, error) {
+	switch encoding {
+	case "text":
+		return ReadText(r)
+	case "json":
+		return ReadJSON(r)
+	case "csv":
+		return ReadCSV(r)
+	}
+	return nil, fmt.Errorf("%w: %q", ErrEncoding, encoding)
+}
+
+// document is the JSON representation of a draw
+type document struct {
+	Schema    int       `json:"schema"`
+	Version   string    `json:"algorithm_version"`
+	Seed      int64     `json:"seed"`
+	Format    Format    `json:"format"`
+	Matchdays int       `json:"matchdays"`
+	Pots      []Pot     `json:"pots"`
+	Fixtures  []Fixture `json:"fixtures"`
+	Balance   []Balance `json:"balance"`
+}
+
+// WriteJSON writes the draw as an indented JSON document
+func WriteJSON(w io.Writer, d *Draw) error {
+	doc := document{
+		Schema:    SchemaVersion,
+		Version:   d.Version,
+		Seed:      d.Seed,
+		Format:    d.Format,
+		Matchdays: len(d.Matchdays()),
+		Pots:      d.Pots,
+		Fixtures:  d.Fixtures,
+		Balance:   d.Balances(),
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
+
+// ReadJSON reads a draw written by WriteJSON. The balance is not read back
+// as it follows from the fixtures.
+func ReadJSON(r io.Reader) (*Draw, error) {
+	var doc document
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	if err := checkSchema(doc.Schema); err != nil {
+		return nil, err
+	}
+
+	return &Draw{
+		Seed:     doc.Seed,
+		Version:  doc.Version,
+		Format:   doc.Format,
+		Pots:     doc.Pots,
+		Fixtures: doc.Fixtures,
+	}, nil
+}
+
+// WriteCSV writes the draw as CSV records tagged by their first field:
+//
+//	schema,<SchemaVersion>
+//	version,<algorithm version>
+//	seed,<seed>
+//	format,<name>,<teams>,<pots>,<matches per pot>
+//	pot,<pot>,<team>,<country>,<coefficient>
+//	fixture,<matchday>,<home>,<away>
+//	balance,<team>,<home games>,<away games>
+//
+// The matchday is 0 for unscheduled draws.
+func WriteCSV(w io.Writer, d *Draw) error {
+	cw := csv.NewWriter(w)
+
+	f := d.Format
+	cw.Write([]string{"schema", strconv.Itoa(SchemaVersion)})
+	cw.Write([]string{"version", d.Version})
+	cw.Write([]string{"seed", strconv.FormatInt(d.Seed, 10)})
+	cw.Write([]string{"format", f.Name, strconv.Itoa(f.Teams), strconv.Itoa(f.Pots), strconv.Itoa(f.MatchesPerPot)})
+
+	for _, pot := range d.Pots {
+		for _, team := range pot.Teams {
+			cw.Write([]string{"pot", pot.Name, team.Name, team.Country, strconv.FormatFloat(team.Coefficient, 'f', -1, 64)})
+		}
+	}
+
+	for _, fixture := range d.Fixtures {
+		cw.Write([]string{"fixture", strconv.Itoa(fixture.Matchday), fixture.Home, fixture.Away})
+	}
+
+	for _, b := range d.Balances() {
+		cw.Write([]string{"balance", b.Team, strconv.Itoa(b.Home), strconv.Itoa(b.Away)})
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// ReadCSV reads a draw written by WriteCSV. Balance records are skipped as
+// they follow from the fixtures.
+func ReadCSV(r io.Reader) (*Draw, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Draw{}
+	pots := make(map[string]int)
+	schema := 0
+
+	for i, record := range records {
+		line := i + 1
+		if err := checkFields(record, line); err != nil {
+			return nil, err
+		}
+
+		switch record[0] {
+		case "schema":
+			if schema, err = strconv.Atoi(record[1]); err != nil {
+				return nil, fmt.Errorf("line %d: invalid schema %q", line, record[1])
+			}
+			if err := checkSchema(schema); err != nil {
+				return nil, err
+			}
+
+		case "version":
+			d.Version = record[1]
+
+		case "seed":
+			if d.Seed, err = strconv.ParseInt(record[1], 10, 64); err != nil {
+				return nil, fmt.Errorf("line %d: invalid seed %q", line, record[1])
+			}
+
+		case "format":
+			d.Format.Name = record[1]
+			numbers := []*int{&d.Format.Teams, &d.Format.Pots, &d.Format.MatchesPerPot}
+			for j, n := range numbers {
+				if *n, err = strconv.Atoi(record[j+2]); err != nil {
+					return nil, fmt.Errorf("line %d: invalid number %q", line, record[j+2])
+				}
+			}
+
+		case "pot":
+			team := Team{Name: record[2], Country: record[3]}
+			if team.Coefficient, err = strconv.ParseFloat(record[4], 64); err != nil {
+				return nil, fmt.Errorf("line %d: invalid coefficient %q", line, record[4])
+			}
+
+			p, ok := pots[record[1]]
+			if !ok {
+				p = len(d.Pots)
+				pots[record[1]] = p
+				d.Pots = append(d.Pots, Pot{Name: record[1]})
+			}
+			d.Pots[p].Teams = append(d.Pots[p].Teams, team)
+
+		case "fixture":
+			fixture := Fixture{Home: record[2], Away: record[3]}
+			if fixture.Matchday, err = strconv.Atoi(record[1]); err != nil {
+				return nil, fmt.Errorf("line %d: invalid matchday %q", line, record[1])
+			}
+			d.Fixtures = append(d.Fixtures, fixture)
+		}
+	}
+
+	if schema == 0 {
+		return nil, fmt.Errorf("%w: missing schema record", ErrSchemaVersion)
+	}
+
+	return d, nil
+}
+
+// csvFields is the number of fields of every CSV record kind
+var csvFields = map[string]int{
+	"schema":  2,
+	"version": 2,
+	"seed":    2,
+	"format":  5,
+	"pot":     5,
+	"fixture": 4,
+	"balance": 4,
+}
+
+func checkFields(record []string, line int) error {
+	want, ok := csvFields[record[0]]
+	if !ok {
+		return fmt.Errorf("line %d: unknown record %q", line, record[0])
+	}
+	if len(record) != want {
+		return fmt.Errorf("line %d: %s record has %d fields, want %d", line, record[0], len(record), want)
+	}
+	return nil
+}
+
+func checkSchema(schema int) error {
+	if schema < minSchemaVersion || schema > SchemaVersion {
+		return fmt.Errorf("%w: got %d, want %d to %d", ErrSchemaVersion, schema, minSchemaVersion, SchemaVersion)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package draw
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEncodingRoundTrip(t *testing.T) {
+	teams, err := ReadTeamsFile("../../example/teams.txt")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	d, err := NewEngine(&Config{Seed: 11}).Run(teams)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := Schedule(d); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for _, encoding := range []string{"json", "csv"} {
+		var buf bytes.Buffer
+		if err := Write(&buf, d, encoding); err != nil {
+			t.Fatalf("Expected no error writing %s, got %v", encoding, err)
+		}
+
+		read, err := Read(&buf, encoding)
+		if err != nil {
+			t.Fatalf("Expected no error reading %s, got %v", encoding, err)
+		}
+
+		if !reflect.DeepEqual(d, read) {
+			t.Errorf("Expected %s round trip to give the same draw", encoding)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, d); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if want := "balance," + d.Pots[0].Teams[0].Name + ",4,4\n"; !strings.Contains(buf.String(), want) {
+		t.Errorf("Expected CSV to contain %q", want)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	if _, err := Read(strings.NewReader(""), "yaml"); !errors.Is(err, ErrEncoding) {
+		t.Errorf("Expected ErrEncoding, got %v", err)
+	}
+
+	if _, err := ReadJSON(strings.NewReader(`{"schema": 99}`)); !errors.Is(err, ErrSchemaVersion) {
+		t.Errorf("Expected ErrSchemaVersion, got %v", err)
+	}
+
+	if _, err := ReadCSV(strings.NewReader("fixture,1,A\n")); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("Expected line 1 error, got %v", err)
+	}
+
+	if _, err := ReadCSV(strings.NewReader("schema,1\nfixture,0,A,B\n")); err != nil {
+		t.Errorf("Expected schema 1 files to be readable, got %v", err)
+	}
+
+	if _, err := ReadCSV(strings.NewReader("seed,1\n")); !errors.Is(err, ErrSchemaVersion) {
+		t.Errorf("Expected ErrSchemaVersion for missing schema, got %v", err)
+	}
+}
+
+func TestReadText(t *testing.T) {
+	teams, err := ReadTeamsFile("../../example/teams.txt")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	d, err := NewEngine(&Config{Seed: 5, Format: ConferenceLeague}).Run(teams)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for _, schedule := range []bool{false, true} {
+		if schedule {
+			if err := Schedule(d); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+		}
+
+		var buf bytes.Buffer
+		if err := WriteText(&buf, d); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		read, err := Read(&buf, "text")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if read.Seed != d.Seed || read.Version != d.Version || read.Format != d.Format {
+			t.Errorf("Expected seed %d, version %s and format %v, got %d, %s and %v",
+				d.Seed, d.Version, d.Format, read.Seed, read.Version, read.Format)
+		}
+		// scheduled matches are written grouped by matchday
+		want := d.Fixtures
+		if schedule {
+			want = nil
+			for _, fixtures := range d.Matchdays() {
+				want = append(want, fixtures...)
+			}
+		}
+		if !reflect.DeepEqual(read.Fixtures, want) {
+			t.Error("Expected text round trip to keep the fixtures")
+		}
+		if len(read.Pots) != len(d.Pots) || read.Pots[0].Teams[0].Name != d.Pots[0].Teams[0].Name {
+			t.Error("Expected text round trip to keep the pots")
+		}
+	}
+
+	if _, err := ReadText(strings.NewReader("Matches:\nReal Madrid v Porto\n")); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Expected line 2 error, got %v", err)
+	}
+}
@@ -0,0 +1,125 @@
+package draw
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Config holds draw engine configuration
+type Config struct {
+	Seed int64
+	// Format is the shape of the league phase, ChampionsLeague when unset
+	Format Format
+	// Constraints restrict which fixtures may be drawn. When nil the
+	// DefaultConstraints are used; an empty slice disables them.
+	Constraints []Constraint
+}
+
+// DefaultConfig returns a configuration seeded from the current time
+func DefaultConfig() *Config {
+	return &Config{
+		Seed:        time.Now().UnixNano(),
+		Format:      ChampionsLeague,
+		Constraints: DefaultConstraints(),
+	}
+}
+
+// Engine runs league phase draws
+type Engine struct {
+	config      *Config
+	format      Format
+	constraints []Constraint
+	rand        *rand.Rand
+}
+
+// NewEngine creates a new draw engine with the given configuration
+func NewEngine(config *Config) *Engine {
+	if config == nil {
+		config = DefaultConfig()
+	}
+
+	format := config.Format
+	if format == (Format{}) {
+		format = ChampionsLeague
+	}
+
+	constraints := config.Constraints
+	if constraints == nil {
+		constraints = DefaultConstraints()
+	}
+
+	return &Engine{
+		config:      config,
+		format:      format,
+		constraints: constraints,
+		rand:        rand.New(rand.NewSource(config.Seed)),
+	}
+}
+
+// Seed returns the seed the engine was created with
+func (e *Engine) Seed() int64 {
+	return e.config.Seed
+}
+
+// Format returns the format the engine draws
+func (e *Engine) Format() Format {
+	return e.format
+}
+
+// Run seeds the teams into pots and draws the format's opponents from every
+// pot for each team, honouring the configured constraints. Only the
+// opponent draw is random; pots are fixed by SeedPots.
+//
+// With two matches per pot every team hosts one opponent and visits another
+// from each pot. With a single match per pot every team plays as many home
+// as away games.
+func (e *Engine) Run(teams []Team) (*Draw, error) {
+	if err := e.format.Validate(); err != nil {
+		return nil, err
+	}
+
+	if len(teams) != e.format.Teams {
+		return nil, &TeamCountError{Want: e.format.Teams, Got: len(teams)}
+	}
+
+	for _, c := range e.constraints {
+		if checker, ok := c.(Checker); ok {
+			if err := checker.Check(teams, e.format); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	pots, err := SeedPots(teams, e.format)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := e.checkPots(pots); err != nil {
+		return nil, err
+	}
+
+	fixtures, err := newSolver(pots, e.format, e.constraints, e.rand).solve()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Draw{
+		Seed:     e.config.Seed,
+		Version:  AlgorithmVersion,
+		Format:   e.format,
+		Pots:     pots,
+		Fixtures: fixtures,
+	}, nil
+}
+
+func (e *Engine) checkPots(pots []Pot) error {
+	for _, c := range e.constraints {
+		if checker, ok := c.(PotChecker); ok {
+			if err := checker.CheckPots(pots); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
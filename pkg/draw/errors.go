@@ -0,0 +1,80 @@
+package draw
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrTeamCount is returned when the number of teams does not match the format
+	ErrTeamCount = errors.New("draw: wrong number of teams")
+	// ErrPotSize is returned when a pot does not hold the expected number of teams
+	ErrPotSize = errors.New("draw: wrong pot size")
+	// ErrInvalidFormat is returned when a format cannot produce a valid draw
+	ErrInvalidFormat = errors.New("draw: invalid format")
+	// ErrDuplicateTeam is returned when two teams share a name
+	ErrDuplicateTeam = errors.New("draw: duplicate team")
+	// ErrInvalidPot is returned when a team names a pot that does not exist
+	ErrInvalidPot = errors.New("draw: invalid pot")
+	// ErrVersionMismatch is returned when replaying a manifest written by a
+	// different algorithm version
+	ErrVersionMismatch = errors.New("draw: algorithm version mismatch")
+	// ErrChecksumMismatch is returned when a replayed draw differs from the
+	// one recorded in its manifest
+	ErrChecksumMismatch = errors.New("draw: checksum mismatch")
+	// ErrCommitmentMismatch is returned when a revealed server seed does not
+	// match the commitment published before the draw
+	ErrCommitmentMismatch = errors.New("draw: commitment mismatch")
+	// ErrNoParticipantSeed is returned when a commit-reveal draw has no
+	// participant seed, leaving the draw seed to the operator alone
+	ErrNoParticipantSeed = errors.New("draw: missing participant seed")
+	// ErrSeedMismatch is returned when the draw seed was not derived from
+	// the revealed seeds
+	ErrSeedMismatch = errors.New("draw: seed mismatch")
+	// ErrInvalidTeams is returned when a team file has malformed rows,
+	// duplicates or unknown countries
+	ErrInvalidTeams = errors.New("draw: invalid team file")
+	// ErrInvalidDraw is returned when a draw breaks the rules of its format
+	ErrInvalidDraw = errors.New("draw: invalid draw")
+	// ErrEncoding is returned for unknown result encodings
+	ErrEncoding = errors.New("draw: unsupported encoding")
+	// ErrSchemaVersion is returned when reading a result file written with
+	// an unsupported schema version
+	ErrSchemaVersion = errors.New("draw: unsupported schema version")
+	// ErrUnschedulable is returned when the fixtures cannot be split into
+	// matchdays
+	ErrUnschedulable = errors.New("draw: fixtures cannot be scheduled")
+	// ErrUnsatisfiable is returned when no draw satisfies the country constraints
+	ErrUnsatisfiable = errors.New("draw: constraints cannot be satisfied")
+)
+
+// TeamCountError reports how many teams were expected and how many were given
+type TeamCountError struct {
+	Want int
+	Got  int
+}
+
+func (e *TeamCountError) Error() string {
+	return fmt.Sprintf("draw: expected %d teams, got %d", e.Want, e.Got)
+}
+
+func (e *TeamCountError) Unwrap() error {
+	return ErrTeamCount
+}
+
+// ConstraintError explains why the constraints for a country cannot be met
+type ConstraintError struct {
+	Country string
+	Reason  string
+}
+
+func (e *ConstraintError) Error() string {
+	if e.Country == "" {
+		return fmt.Sprintf("%v: %s", ErrUnsatisfiable, e.Reason)
+	}
+	return fmt.Sprintf("%v: country %s: %s", ErrUnsatisfiable, e.Country, e.Reason)
+}
+
+func (e *ConstraintError) Unwrap() error {
+	return ErrUnsatisfiable
+}
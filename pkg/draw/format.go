@@ -0,0 +1,99 @@
+package draw
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Format describes the shape of a league phase
+type Format struct {
+	Name string `json:"name"`
+	// Teams is the number of teams taking part
+	Teams int `json:"teams"`
+	// Pots is the number of pots teams are seeded into
+	Pots int `json:"pots"`
+	// MatchesPerPot is the number of opponents every team gets from each
+	// pot: 2 for one home and one away game, 1 for a single game
+	MatchesPerPot int `json:"matches_per_pot"`
+}
+
+var (
+	// ChampionsLeague is 36 teams in 4 pots, two opponents from each pot
+	ChampionsLeague = Format{Name: "ucl", Teams: 36, Pots: 4, MatchesPerPot: 2}
+	// EuropaLeague is 36 teams in 4 pots, two opponents from each pot
+	EuropaLeague = Format{Name: "uel", Teams: 36, Pots: 4, MatchesPerPot: 2}
+	// ConferenceLeague is 36 teams in 6 pots, one opponent from each pot
+	ConferenceLeague = Format{Name: "uecl", Teams: 36, Pots: 6, MatchesPerPot: 1}
+	// SchoolTournament is 24 teams in 4 pots, two opponents from each pot
+	SchoolTournament = Format{Name: "school", Teams: 24, Pots: 4, MatchesPerPot: 2}
+)
+
+var profiles = map[string]Format{
+	ChampionsLeague.Name:  ChampionsLeague,
+	EuropaLeague.Name:     EuropaLeague,
+	ConferenceLeague.Name: ConferenceLeague,
+	SchoolTournament.Name: SchoolTournament,
+}
+
+// Profile returns the predefined format with the given name
+func Profile(name string) (Format, bool) {
+	f, ok := profiles[name]
+	return f, ok
+}
+
+// Profiles returns the names of the predefined formats
+func Profiles() []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PotSize returns the number of teams in every pot
+func (f Format) PotSize() int {
+	return f.Teams / f.Pots
+}
+
+// MatchesPerTeam returns the number of games every team plays
+func (f Format) MatchesPerTeam() int {
+	return f.Pots * f.MatchesPerPot
+}
+
+// Matches returns the total number of fixtures
+func (f Format) Matches() int {
+	return f.Teams * f.MatchesPerTeam() / 2
+}
+
+// Validate checks that a draw with this format can exist and be scheduled.
+// Teams must play an even number of matches: Schedule builds matchdays in
+// pairs in which every team plays once at home and once away, and an odd
+// number of matches cannot always be split into matchdays at all.
+func (f Format) Validate() error {
+	switch {
+	case f.Teams <= 0 || f.Pots <= 0:
+		return fmt.Errorf("%w: teams and pots must be positive", ErrInvalidFormat)
+	case f.Pots > 26:
+		return fmt.Errorf("%w: at most 26 pots are supported, got %d", ErrInvalidFormat, f.Pots)
+	case f.Teams%f.Pots != 0:
+		return fmt.Errorf("%w: %d teams cannot be split into %d equal pots", ErrInvalidFormat, f.Teams, f.Pots)
+	case f.MatchesPerPot != 1 && f.MatchesPerPot != 2:
+		return fmt.Errorf("%w: matches per pot must be 1 or 2, got %d", ErrInvalidFormat, f.MatchesPerPot)
+	case f.MatchesPerPot == 2 && f.PotSize() < 3:
+		// home and away opponents from a team's own pot must differ
+		return fmt.Errorf("%w: two matches per pot need at least 3 teams per pot, got %d", ErrInvalidFormat, f.PotSize())
+	case f.MatchesPerPot == 1 && f.PotSize()%2 != 0:
+		// teams of a pot are paired with each other
+		return fmt.Errorf("%w: one match per pot needs an even pot size, got %d", ErrInvalidFormat, f.PotSize())
+	case f.MatchesPerTeam()%2 != 0:
+		// every team plays as many home as away matches
+		return fmt.Errorf("%w: teams must play an even number of matches, got %d", ErrInvalidFormat, f.MatchesPerTeam())
+	}
+
+	return nil
+}
+
+func (f Format) String() string {
+	return fmt.Sprintf("%s (%d teams, %d pots, %d matches per pot)", f.Name, f.Teams, f.Pots, f.MatchesPerPot)
+}
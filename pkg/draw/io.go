@@ -0,0 +1,167 @@
+package draw
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// WriteText writes the seed and algorithm version, the pots, the list of
+// matches, grouped by matchday once the draw is scheduled, and the home and
+// away balance of every team
+func WriteText(w io.Writer, d *Draw) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "Seed: %d\n", d.Seed)
+	fmt.Fprintf(bw, "Version: %s\n\n", d.Version)
+
+	for _, pot := range d.Pots {
+		fmt.Fprintf(bw, "Group %s:\n", pot.Name)
+		for _, team := range pot.Teams {
+			fmt.Fprintln(bw, team.Name)
+		}
+		fmt.Fprintln(bw) // add an empty line between pots
+	}
+
+	if matchdays := d.Matchdays(); matchdays != nil {
+		for i, fixtures := range matchdays {
+			if i > 0 {
+				fmt.Fprintln(bw)
+			}
+			fmt.Fprintf(bw, "Matchday %d:\n", i+1)
+			for _, fixture := range fixtures {
+				fmt.Fprintln(bw, fixture)
+			}
+		}
+	} else {
+		fmt.Fprintln(bw, "Matches:")
+		for _, fixture := range d.Fixtures {
+			fmt.Fprintln(bw, fixture)
+		}
+	}
+
+	fmt.Fprintln(bw)
+	fmt.Fprintln(bw, "Balance:")
+	for _, b := range d.Balances() {
+		fmt.Fprintf(bw, "%s: %d home, %d away\n", b.Team, b.Home, b.Away)
+	}
+
+	return bw.Flush()
+}
+
+// WriteTextFile writes the draw to the file at path
+func WriteTextFile(path string, d *Draw) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := WriteText(file, d); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// ReadText reads a draw written by WriteText. The text encoding records
+// neither the format nor team details, so teams only carry their names
+// and the format is inferred from the pots and fixtures. The balance is
+// skipped as it follows from the fixtures.
+func ReadText(r io.Reader) (*Draw, error) {
+	d := &Draw{}
+	scanner := bufio.NewScanner(r)
+
+	// section is the pot, fixture list or balance the next lines belong to
+	section := ""
+	matchday := 0
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(text, "Seed:"):
+			seed, err := strconv.ParseInt(strings.TrimSpace(strings.TrimPrefix(text, "Seed:")), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid seed %q", line, text)
+			}
+			d.Seed = seed
+
+		case strings.HasPrefix(text, "Version:"):
+			d.Version = strings.TrimSpace(strings.TrimPrefix(text, "Version:"))
+
+		case strings.HasPrefix(text, "Group ") && strings.HasSuffix(text, ":"):
+			section = "pot"
+			name := strings.TrimSuffix(strings.TrimPrefix(text, "Group "), ":")
+			d.Pots = append(d.Pots, Pot{Name: name})
+
+		case text == "Matches:":
+			section = "fixtures"
+			matchday = 0
+
+		case strings.HasPrefix(text, "Matchday ") && strings.HasSuffix(text, ":"):
+			n, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(text, "Matchday "), ":"))
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("line %d: invalid matchday %q", line, text)
+			}
+			section = "fixtures"
+			matchday = n
+
+		case text == "Balance:":
+			section = "balance"
+
+		case section == "balance":
+			// derived from the fixtures
+
+		case section == "pot":
+			pot := &d.Pots[len(d.Pots)-1]
+			pot.Teams = append(pot.Teams, Team{Name: text})
+
+		case section == "fixtures":
+			a, b, ok := strings.Cut(text, " - ")
+			if !ok {
+				return nil, fmt.Errorf("line %d: expected a match \"home - away\", got %q", line, text)
+			}
+			d.Fixtures = append(d.Fixtures, Fixture{Home: strings.TrimSpace(a), Away: strings.TrimSpace(b), Matchday: matchday})
+
+		default:
+			return nil, fmt.Errorf("line %d: unexpected %q", line, text)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	d.Format = inferFormat(d.Pots, len(d.Fixtures))
+	return d, nil
+}
+
+// inferFormat returns the predefined format matching the number of teams,
+// pots and fixtures, or an unnamed format of the same shape
+func inferFormat(pots []Pot, fixtures int) Format {
+	f := Format{Pots: len(pots)}
+	for _, pot := range pots {
+		f.Teams += len(pot.Teams)
+	}
+	if f.Teams > 0 && f.Pots > 0 {
+		f.MatchesPerPot = 2 * fixtures / (f.Teams * f.Pots)
+	}
+
+	for _, name := range Profiles() {
+		profile, _ := Profile(name)
+		shape := profile
+		shape.Name = ""
+		if shape == f {
+			return profile
+		}
+	}
+
+	return f
+}
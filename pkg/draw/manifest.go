@@ -0,0 +1,122 @@
+package draw
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Manifest records everything needed to re-run a draw and check that the
+// result is byte-identical. Draws made with custom constraints cannot be
+// replayed from a manifest.
+type Manifest struct {
+	Version  string `json:"version"`
+	Seed     int64  `json:"seed"`
+	Format   Format `json:"format"`
+	Teams    []Team `json:"teams"`
+	Checksum string `json:"checksum"`
+	// Reveal is set for commit-reveal draws
+	Reveal *Reveal `json:"reveal,omitempty"`
+}
+
+// NewManifest creates the manifest for a draw made from the given teams
+func NewManifest(d *Draw, teams []Team) (*Manifest, error) {
+	checksum, err := Checksum(d)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Manifest{
+		Version:  d.Version,
+		Seed:     d.Seed,
+		Format:   d.Format,
+		Teams:    teams,
+		Checksum: checksum,
+	}, nil
+}
+
+// Checksum returns the hex encoded SHA-256 of the draw's text output
+func Checksum(d *Draw) (string, error) {
+	var buf bytes.Buffer
+	if err := WriteText(&buf, d); err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(buf.Bytes())
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Replay re-runs the draw described by the manifest and verifies that it
+// matches the recorded checksum. A manifest without a checksum ties the
+// replay to no published draw and is rejected.
+func Replay(m *Manifest) (*Draw, error) {
+	if m.Checksum == "" {
+		return nil, fmt.Errorf("%w: manifest has no checksum", ErrChecksumMismatch)
+	}
+
+	if m.Version != AlgorithmVersion {
+		return nil, fmt.Errorf("%w: manifest has %s, engine has %s", ErrVersionMismatch, m.Version, AlgorithmVersion)
+	}
+
+	d, err := NewEngine(&Config{Seed: m.Seed, Format: m.Format}).Run(m.Teams)
+	if err != nil {
+		return nil, err
+	}
+
+	checksum, err := Checksum(d)
+	if err != nil {
+		return nil, err
+	}
+
+	if checksum != m.Checksum {
+		return nil, fmt.Errorf("%w: manifest has %s, replay has %s", ErrChecksumMismatch, m.Checksum, checksum)
+	}
+
+	return d, nil
+}
+
+// WriteManifest writes the manifest as indented JSON
+func WriteManifest(w io.Writer, m *Manifest) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(m)
+}
+
+// ReadManifest reads a manifest written by WriteManifest
+func ReadManifest(r io.Reader) (*Manifest, error) {
+	var m Manifest
+	if err := json.NewDecoder(r).Decode(&m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// WriteManifestFile writes the manifest to the file at path
+func WriteManifestFile(path string, m *Manifest) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := WriteManifest(file, m); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// ReadManifestFile reads a manifest from the file at path
+func ReadManifestFile(path string) (*Manifest, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ReadManifest(file)
+}
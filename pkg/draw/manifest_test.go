@@ -0,0 +1,64 @@
+package draw
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestReplay(t *testing.T) {
+	teams, err := ReadTeamsFile("../../example/teams.txt")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	d, err := NewEngine(nil).Run(teams)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	m, err := NewManifest(d, teams)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteManifest(&buf, m); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	read, err := ReadManifest(&buf)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	replayed, err := Replay(read)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var want, got bytes.Buffer
+	WriteText(&want, d)
+	WriteText(&got, replayed)
+	if want.String() != got.String() {
+		t.Error("Expected replayed draw to be byte-identical")
+	}
+
+	read.Seed++
+	if _, err := Replay(read); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("Expected ErrChecksumMismatch, got %v", err)
+	}
+
+	read.Seed--
+	checksum := read.Checksum
+	read.Checksum = ""
+	if _, err := Replay(read); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("Expected ErrChecksumMismatch for a manifest without checksum, got %v", err)
+	}
+	read.Checksum = checksum
+
+	read.Version = "old"
+	if _, err := Replay(read); !errors.Is(err, ErrVersionMismatch) {
+		t.Errorf("Expected ErrVersionMismatch, got %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package draw
+
+import (
+	"fmt"
+	"sort"
+)
+
+// SeedPots splits teams into the pots of the format. Team names must be
+// unique. Teams with an explicit pot are placed there first; the rest are
+// ranked by coefficient, highest first, and fill the pots in order. Teams
+// with equal coefficients keep their input order.
+func SeedPots(teams []Team, format Format) ([]Pot, error) {
+	if err := format.Validate(); err != nil {
+		return nil, err
+	}
+
+	if len(teams) != format.Teams {
+		return nil, &TeamCountError{Want: format.Teams, Got: len(teams)}
+	}
+
+	names := make(map[string]bool, len(teams))
+	for _, team := range teams {
+		if names[team.Name] {
+			return nil, fmt.Errorf("%w: %q", ErrDuplicateTeam, team.Name)
+		}
+		names[team.Name] = true
+	}
+
+	size := format.PotSize()
+	pots := make([]Pot, format.Pots)
+	index := make(map[string]int, format.Pots)
+	for i := range pots {
+		pots[i] = Pot{Name: PotName(i), Teams: make([]Team, 0, size)}
+		index[pots[i].Name] = i
+	}
+
+	var ranked []Team
+	for _, team := range teams {
+		if team.Pot == "" {
+			ranked = append(ranked, team)
+			continue
+		}
+
+		i, ok := index[team.Pot]
+		if !ok {
+			return nil, fmt.Errorf("%w: team %s has unknown pot %q", ErrInvalidPot, team.Name, team.Pot)
+		}
+		if len(pots[i].Teams) == size {
+			return nil, fmt.Errorf("%w: pot %s has more than %d teams", ErrPotSize, team.Pot, size)
+		}
+		pots[i].Teams = append(pots[i].Teams, team)
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Coefficient > ranked[j].Coefficient
+	})
+
+	i := 0
+	for _, team := range ranked {
+		for len(pots[i].Teams) == size {
+			i++
+		}
+		pots[i].Teams = append(pots[i].Teams, team)
+	}
+
+	return pots, nil
+}
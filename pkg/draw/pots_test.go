@@ -0,0 +1,79 @@
+package draw
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSeedPots(t *testing.T) {
+	teams := testTeams(ChampionsLeague.Teams)
+	for i := range teams {
+		// lowest coefficient first so ranking reverses the order
+		teams[i].Coefficient = float64(i)
+	}
+	teams[0].Pot = "A"
+
+	pots, err := SeedPots(teams, ChampionsLeague)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if pots[0].Teams[0].Name != "Team 01" {
+		t.Errorf("Expected Team 01 to be placed in pot A first, got %s", pots[0].Teams[0].Name)
+	}
+
+	if pots[0].Teams[1].Name != "Team 36" {
+		t.Errorf("Expected Team 36 to be ranked first, got %s", pots[0].Teams[1].Name)
+	}
+
+	// pot A holds the explicit team and the eight best ranked ones
+	if last := pots[0].Teams[ChampionsLeague.PotSize()-1].Name; last != "Team 29" {
+		t.Errorf("Expected Team 29 to close pot A, got %s", last)
+	}
+
+	if first := pots[1].Teams[0].Name; first != "Team 28" {
+		t.Errorf("Expected Team 28 to open pot B, got %s", first)
+	}
+
+	for _, pot := range pots {
+		if len(pot.Teams) != ChampionsLeague.PotSize() {
+			t.Errorf("Expected pot %s to have %d teams, got %d", pot.Name, ChampionsLeague.PotSize(), len(pot.Teams))
+		}
+	}
+}
+
+func TestSeedPotsErrors(t *testing.T) {
+	teams := testTeams(ChampionsLeague.Teams)
+	teams[0].Pot = "Z"
+	if _, err := SeedPots(teams, ChampionsLeague); !errors.Is(err, ErrInvalidPot) {
+		t.Errorf("Expected ErrInvalidPot, got %v", err)
+	}
+
+	teams = testTeams(ChampionsLeague.Teams)
+	for i := range ChampionsLeague.PotSize() + 1 {
+		teams[i].Pot = "B"
+	}
+	if _, err := SeedPots(teams, ChampionsLeague); !errors.Is(err, ErrPotSize) {
+		t.Errorf("Expected ErrPotSize, got %v", err)
+	}
+
+	if _, err := SeedPots(nil, Format{}); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("Expected ErrInvalidFormat, got %v", err)
+	}
+}
+
+func TestReadTeamsCoefficient(t *testing.T) {
+	teams, err := ReadTeams(strings.NewReader("Porto,POR,77.5\nPorto 2,POR,,D\n"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if teams[0].Coefficient != 77.5 || teams[1].Pot != "D" {
+		t.Errorf("Unexpected teams: %v", teams)
+	}
+
+	if _, err := ReadTeams(strings.NewReader("Porto,POR,abc\n")); err == nil || !strings.Contains(err.Error(), ":1:") {
+		t.Errorf("Expected line 1 error, got %v", err)
+	}
+}
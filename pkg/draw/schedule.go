@@ -0,0 +1,237 @@
+package draw
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+const (
+	// maxScheduleAttempts is how many randomised attempts are made before
+	// giving up
+	maxScheduleAttempts = 1000
+	// maxMatchingTries is how many random matchings are tried for each pair
+	// of matchdays within an attempt
+	maxMatchingTries = 50
+)
+
+// Schedule assigns every fixture of the draw a matchday so that each team
+// plays exactly once per matchday and never more than two home or two away
+// games in a row.
+//
+// Matchdays are built in pairs. Every pair is a set of fixtures in which
+// each team hosts exactly once and visits exactly once; those fixtures form
+// cycles of teams, and alternate fixtures along each cycle go to the first
+// and the second matchday of the pair. Every team therefore plays one home
+// and one away game per pair, so a run of home or away games can only span
+// the end of one pair and the start of the next. The order inside each
+// cycle is chosen to keep teams alternating across pairs where possible.
+//
+// The schedule is derived from the draw's seed, so scheduling the same
+// draw always gives the same matchdays.
+func Schedule(d *Draw) error {
+	s, err := newScheduler(d)
+	if err != nil {
+		return err
+	}
+
+	r := rand.New(rand.NewSource(d.Seed))
+	for range maxScheduleAttempts {
+		if rounds, ok := s.attempt(r); ok {
+			for f, round := range rounds {
+				d.Fixtures[f].Matchday = round + 1
+			}
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: no schedule found after %d attempts", ErrUnschedulable, maxScheduleAttempts)
+}
+
+// scheduler splits fixtures into pairs of matchdays
+type scheduler struct {
+	teams    int
+	pairs    int
+	fixtures [][2]int // home and away team index of every fixture
+	hosting  [][]int  // fixtures hosted by every team
+}
+
+func newScheduler(d *Draw) (*scheduler, error) {
+	index := make(map[string]int)
+	for _, pot := range d.Pots {
+		for _, team := range pot.Teams {
+			index[team.Name] = len(index)
+		}
+	}
+
+	if len(index) == 0 || len(d.Fixtures) == 0 {
+		return nil, fmt.Errorf("%w: the draw has no teams or no fixtures", ErrUnschedulable)
+	}
+
+	s := &scheduler{
+		teams:   len(index),
+		hosting: make([][]int, len(index)),
+	}
+
+	hosted := make([]int, len(index))
+	visited := make([]int, len(index))
+	for i, f := range d.Fixtures {
+		h, okH := index[f.Home]
+		a, okA := index[f.Away]
+		if !okH || !okA {
+			return nil, fmt.Errorf("%w: fixture %s has a team outside the pots", ErrUnschedulable, f)
+		}
+
+		s.fixtures = append(s.fixtures, [2]int{h, a})
+		s.hosting[h] = append(s.hosting[h], i)
+		hosted[h]++
+		visited[a]++
+	}
+
+	// pairs of matchdays need every team to host as often as it visits
+	s.pairs = hosted[0]
+	for t := range hosted {
+		if hosted[t] != s.pairs || visited[t] != s.pairs {
+			return nil, fmt.Errorf("%w: teams must all play the same number of home and away games", ErrUnschedulable)
+		}
+	}
+
+	return s, nil
+}
+
+// attempt tries to build every pair of matchdays, returning the matchday of
+// every fixture
+func (s *scheduler) attempt(r *rand.Rand) ([]int, bool) {
+	used := make([]bool, len(s.fixtures))
+	rounds := make([]int, len(s.fixtures))
+	last := make([]int, s.teams) // venue of every team on the previous matchday
+
+	for pair := range s.pairs {
+		var next []int
+		for range maxMatchingTries {
+			next = s.matching(used, r)
+			if next != nil && evenCycles(next, s.fixtures) {
+				break
+			}
+			next = nil
+		}
+
+		if next == nil {
+			return nil, false
+		}
+
+		for _, f := range next {
+			used[f] = true
+		}
+
+		s.split(next, 2*pair, rounds, last, r)
+	}
+
+	return rounds, true
+}
+
+// matching picks a random set of unused fixtures in which every team hosts
+// exactly once and visits exactly once. The fixtures left after removing
+// such sets always allow another one, since every team hosts as often as
+// it visits.
+func (s *scheduler) matching(used []bool, r *rand.Rand) []int {
+	visitor := make([]int, s.teams) // fixture in which each team visits, or -1
+	for t := range visitor {
+		visitor[t] = -1
+	}
+
+	var augment func(h int, seen []bool) bool
+	augment = func(h int, seen []bool) bool {
+		hosting := s.hosting[h]
+		for _, i := range r.Perm(len(hosting)) {
+			f := hosting[i]
+			a := s.fixtures[f][1]
+			if used[f] || seen[a] {
+				continue
+			}
+			seen[a] = true
+
+			if visitor[a] < 0 || augment(s.fixtures[visitor[a]][0], seen) {
+				visitor[a] = f
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, h := range r.Perm(s.teams) {
+		if !augment(h, make([]bool, s.teams)) {
+			return nil
+		}
+	}
+
+	return visitor
+}
+
+// evenCycles reports whether the cycles formed by following each host to
+// the team it hosts all have even length, so they can be split in two
+func evenCycles(visitor []int, fixtures [][2]int) bool {
+	hosts := make([]int, len(visitor))
+	for a, f := range visitor {
+		hosts[fixtures[f][0]] = a
+	}
+
+	seen := make([]bool, len(visitor))
+	for start := range visitor {
+		length := 0
+		for t := start; !seen[t]; t = hosts[t] {
+			seen[t] = true
+			length++
+		}
+		if length%2 != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// split assigns alternate fixtures of each cycle to the two matchdays
+// starting at round, choosing the order that gives the fewest teams the
+// same venue as on the previous matchday
+func (s *scheduler) split(visitor []int, round int, rounds, last []int, r *rand.Rand) {
+	hosted := make([]int, s.teams) // fixture each team hosts
+	for _, f := range visitor {
+		hosted[s.fixtures[f][0]] = f
+	}
+
+	seen := make([]bool, s.teams)
+	for start := range s.teams {
+		if seen[start] {
+			continue
+		}
+
+		var cycle []int
+		for t := start; !seen[t]; t = s.fixtures[hosted[t]][1] {
+			seen[t] = true
+			cycle = append(cycle, hosted[t])
+		}
+
+		// fixtures at even positions go first, so the host of one of them
+		// plays at home first and away second, and the other way round
+		repeats := 0
+		for i, f := range cycle {
+			h := s.fixtures[f][0]
+			if i%2 == 0 && last[h] == 1 || i%2 != 0 && last[h] == -1 {
+				repeats++
+			}
+		}
+
+		flip := 0
+		if 2*repeats > len(cycle) || 2*repeats == len(cycle) && r.Intn(2) == 0 {
+			flip = 1
+		}
+
+		for i, f := range cycle {
+			h, a := s.fixtures[f][0], s.fixtures[f][1]
+			rounds[f] = round + (i+flip)%2
+			if rounds[f] == round+1 {
+				last[h], last[a] = 1, -1
+			}
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package draw
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSchedule(t *testing.T) {
+	teams, err := ReadTeamsFile("../../example/teams.txt")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for _, format := range []Format{ChampionsLeague, ConferenceLeague} {
+		for seed := range int64(5) {
+			d, err := NewEngine(&Config{Seed: seed, Format: format}).Run(teams)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if d.Scheduled() {
+				t.Fatal("Expected a fresh draw to be unscheduled")
+			}
+
+			if err := Schedule(d); err != nil {
+				t.Fatalf("Expected %s draw with seed %d to be scheduled, got %v", format.Name, seed, err)
+			}
+
+			checkSchedule(t, d)
+		}
+	}
+}
+
+func TestScheduleEmptyDraw(t *testing.T) {
+	draws := map[string]*Draw{
+		"empty":       {},
+		"no fixtures": {Pots: []Pot{{Name: "A", Teams: []Team{{Name: "X"}, {Name: "Y"}}}}},
+	}
+
+	for name, d := range draws {
+		if err := Schedule(d); !errors.Is(err, ErrUnschedulable) {
+			t.Errorf("Expected ErrUnschedulable for %s draw, got %v", name, err)
+		}
+	}
+}
+
+// checkSchedule verifies that every team plays once per matchday and never
+// more than two home or away games in a row
+func checkSchedule(t *testing.T, d *Draw) {
+	t.Helper()
+
+	matchdays := d.Matchdays()
+	if len(matchdays) != d.Format.MatchesPerTeam() {
+		t.Fatalf("Expected %d matchdays, got %d", d.Format.MatchesPerTeam(), len(matchdays))
+	}
+
+	venues := make(map[string][]int)
+	for i, fixtures := range matchdays {
+		if len(fixtures) != d.Format.Teams/2 {
+			t.Errorf("Expected %d fixtures on matchday %d, got %d", d.Format.Teams/2, i+1, len(fixtures))
+		}
+
+		for _, f := range fixtures {
+			venues[f.Home] = append(venues[f.Home], 1)
+			venues[f.Away] = append(venues[f.Away], -1)
+			if len(venues[f.Home]) != i+1 || len(venues[f.Away]) != i+1 {
+				t.Fatalf("Expected %s and %s to play once on matchday %d", f.Home, f.Away, i+1)
+			}
+		}
+	}
+
+	for team, v := range venues {
+		for i := 2; i < len(v); i++ {
+			if v[i] == v[i-1] && v[i] == v[i-2] {
+				t.Errorf("Team %s plays three games in a row at the same venue: %v", team, v)
+				break
+			}
+		}
+	}
+}
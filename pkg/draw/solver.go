@@ -0,0 +1,333 @@
+package draw
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+const (
+	// maxNodes bounds the assignments tried by a single solver attempt
+	maxNodes = 3000
+	// maxAttempts is how many randomised attempts are made before giving up
+	maxAttempts = 200
+)
+
+// solver assigns every team its opponents from each pot using backtracking
+// search with forward checking.
+//
+// With two matches per pot the variables are home[t][p]: the team from pot
+// p hosted by team t. Once every variable is assigned, t's away opponent
+// from pot p is the team of pot p that hosts t.
+//
+// With a single match per pot the variable for team t and pot p is filled
+// by either home[t][p] or away[t][p], and venues are balanced once every
+// opponent is known.
+type solver struct {
+	teams       []Team
+	single      bool    // one match per pot instead of two
+	pot         []int   // pot index of every team
+	pots        [][]int // team indices of every pot
+	constraints []Constraint
+	rand        *rand.Rand
+	index       map[string]int // team index by name
+
+	home  [][]int  // home[t][p] is the team from pot p hosted by t, or -1
+	away  [][]int  // away[t][p] is the team from pot p visited by t, or -1
+	drawn [][]Team // opponents of every team in assignment order
+	nodes int
+}
+
+func newSolver(pots []Pot, format Format, constraints []Constraint, r *rand.Rand) *solver {
+	s := &solver{
+		single:      format.MatchesPerPot == 1,
+		pots:        make([][]int, len(pots)),
+		constraints: constraints,
+		rand:        r,
+		index:       make(map[string]int),
+	}
+
+	for p, pot := range pots {
+		for _, team := range pot.Teams {
+			s.index[team.Name] = len(s.teams)
+			s.pots[p] = append(s.pots[p], len(s.teams))
+			s.teams = append(s.teams, team)
+			s.pot = append(s.pot, p)
+		}
+	}
+
+	return s
+}
+
+// Opponents returns the teams already drawn against the named team
+func (s *solver) Opponents(name string) []Team {
+	t, ok := s.index[name]
+	if !ok {
+		return nil
+	}
+
+	return s.drawn[t]
+}
+
+// solve runs randomised attempts until one completes
+func (s *solver) solve() ([]Fixture, error) {
+	for range maxAttempts {
+		s.reset()
+		if s.search() {
+			if s.single {
+				s.balance()
+			}
+			return s.fixtures(), nil
+		}
+	}
+
+	return nil, &ConstraintError{
+		Reason: fmt.Sprintf("no valid draw found after %d attempts", maxAttempts),
+	}
+}
+
+func (s *solver) reset() {
+	s.home = make([][]int, len(s.teams))
+	s.away = make([][]int, len(s.teams))
+	s.drawn = make([][]Team, len(s.teams))
+	for t := range s.teams {
+		s.home[t] = make([]int, len(s.pots))
+		s.away[t] = make([]int, len(s.pots))
+		s.drawn[t] = make([]Team, 0, 2*len(s.pots))
+		for p := range s.pots {
+			s.home[t][p] = -1
+			s.away[t][p] = -1
+		}
+	}
+	s.nodes = 0
+}
+
+// search assigns the most constrained variable first and backtracks when
+// forward checking leaves some other variable without candidates
+func (s *solver) search() bool {
+	t, p, candidates := s.next()
+	if t < 0 {
+		return true
+	}
+
+	s.rand.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+
+	for _, o := range candidates {
+		if s.nodes >= maxNodes {
+			return false
+		}
+		s.nodes++
+
+		s.assign(t, p, o)
+		if s.forwardCheck() && s.search() {
+			return true
+		}
+		s.unassign(t, p, o)
+	}
+
+	return false
+}
+
+// next picks the unassigned variable with the fewest candidates, returning
+// t < 0 once every variable is assigned
+func (s *solver) next() (int, int, []int) {
+	bestT, bestP := -1, -1
+	var best []int
+
+	for t := range s.teams {
+		for p := range s.pots {
+			if s.assigned(t, p) {
+				continue
+			}
+
+			candidates := s.candidates(t, p)
+			if bestT < 0 || len(candidates) < len(best) {
+				bestT, bestP, best = t, p, candidates
+			}
+		}
+	}
+
+	return bestT, bestP, best
+}
+
+// forwardCheck reports whether every unassigned variable still has a
+// candidate and every team still has a possible host from each pot
+func (s *solver) forwardCheck() bool {
+	for t := range s.teams {
+		for p := range s.pots {
+			if !s.assigned(t, p) && !s.hasCandidate(t, p) {
+				return false
+			}
+			if !s.single && s.away[t][p] < 0 && !s.hasHost(t, p) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// candidates returns the teams of pot p that t may host
+func (s *solver) candidates(t, p int) []int {
+	var candidates []int
+	for _, o := range s.pots[p] {
+		if s.allowed(t, o) {
+			candidates = append(candidates, o)
+		}
+	}
+	return candidates
+}
+
+func (s *solver) hasCandidate(t, p int) bool {
+	for _, o := range s.pots[p] {
+		if s.allowed(t, o) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasHost reports whether some team of pot p may still host o
+func (s *solver) hasHost(o, p int) bool {
+	for _, t := range s.pots[p] {
+		if s.home[t][s.pot[o]] < 0 && s.allowed(t, o) {
+			return true
+		}
+	}
+	return false
+}
+
+// assigned reports whether t already has its opponent from pot p
+func (s *solver) assigned(t, p int) bool {
+	return s.home[t][p] >= 0 || (s.single && s.away[t][p] >= 0)
+}
+
+func (s *solver) allowed(t, o int) bool {
+	if o == t {
+		return false
+	}
+
+	if s.single {
+		// o still needs its single opponent from t's pot
+		if s.assigned(o, s.pot[t]) {
+			return false
+		}
+		return s.allowedByConstraints(t, o)
+	}
+
+	// o already visits a team from t's pot
+	if s.away[o][s.pot[t]] >= 0 {
+		return false
+	}
+
+	// the two teams have already been drawn against each other
+	if s.home[o][s.pot[t]] == t || s.away[t][s.pot[o]] == o {
+		return false
+	}
+
+	return s.allowedByConstraints(t, o)
+}
+
+func (s *solver) allowedByConstraints(t, o int) bool {
+	for _, c := range s.constraints {
+		if !c.Allow(s.teams[t], s.teams[o], s) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s *solver) assign(t, p, o int) {
+	s.home[t][p] = o
+	s.away[o][s.pot[t]] = t
+	s.drawn[t] = append(s.drawn[t], s.teams[o])
+	s.drawn[o] = append(s.drawn[o], s.teams[t])
+}
+
+func (s *solver) unassign(t, p, o int) {
+	s.home[t][p] = -1
+	s.away[o][s.pot[t]] = -1
+	s.drawn[t] = s.drawn[t][:len(s.drawn[t])-1]
+	s.drawn[o] = s.drawn[o][:len(s.drawn[o])-1]
+}
+
+// fixtures lists the home games of every team, pot by pot
+func (s *solver) fixtures() []Fixture {
+	var fixtures []Fixture
+	for t, team := range s.teams {
+		for p := range s.pots {
+			if o := s.home[t][p]; o >= 0 {
+				fixtures = append(fixtures, Fixture{Home: team.Name, Away: s.teams[o].Name})
+			}
+		}
+	}
+	return fixtures
+}
+
+// balance chooses venues for single match formats so that every team plays
+// as many home as away games. Format.Validate ensures every team plays an
+// even number of games, so the fixture graph has closed trails covering
+// every game; hosting each game at the team the trail leaves gives each
+// team one home game for every away game.
+func (s *solver) balance() {
+	type edge struct {
+		u, v int
+		used bool
+	}
+
+	n := len(s.teams)
+	var edges []edge
+	for t := range s.teams {
+		for p := range s.pots {
+			if o := s.home[t][p]; o >= 0 {
+				edges = append(edges, edge{u: t, v: o})
+			}
+		}
+	}
+	s.rand.Shuffle(len(edges), func(i, j int) {
+		edges[i], edges[j] = edges[j], edges[i]
+	})
+
+	adjacent := make([][]int, n)
+	for i, e := range edges {
+		adjacent[e.u] = append(adjacent[e.u], i)
+		adjacent[e.v] = append(adjacent[e.v], i)
+	}
+
+	for t := range s.teams {
+		for p := range s.pots {
+			s.home[t][p] = -1
+			s.away[t][p] = -1
+		}
+	}
+
+	next := make([]int, n)
+	for start := range n {
+		for {
+			// walk a closed trail from start, hosting each game at the
+			// team the trail leaves
+			u, moved := start, false
+			for {
+				for next[u] < len(adjacent[u]) && edges[adjacent[u][next[u]]].used {
+					next[u]++
+				}
+				if next[u] == len(adjacent[u]) {
+					break
+				}
+
+				e := &edges[adjacent[u][next[u]]]
+				e.used = true
+				v := e.u + e.v - u
+				s.home[u][s.pot[v]] = v
+				s.away[v][s.pot[u]] = u
+				u, moved = v, true
+			}
+
+			if !moved {
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,379 @@
+package draw
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// TeamEncodings lists the team file encodings accepted by ParseTeams
+var TeamEncodings = []string{"text", "csv", "json"}
+
+// Countries lists the UEFA member associations by their three letter code
+var Countries = map[string]string{
+	"ALB": "Albania", "AND": "Andorra", "ARM": "Armenia", "AUT": "Austria",
+	"AZE": "Azerbaijan", "BEL": "Belgium", "BIH": "Bosnia and Herzegovina",
+	"BLR": "Belarus", "BUL": "Bulgaria", "CRO": "Croatia", "CYP": "Cyprus",
+	"CZE": "Czechia", "DEN": "Denmark", "ENG": "England", "ESP": "Spain",
+	"EST": "Estonia", "FIN": "Finland", "FRA": "France", "FRO": "Faroe Islands",
+	"GEO": "Georgia", "GER": "Germany", "GIB": "Gibraltar", "GRE": "Greece",
+	"HUN": "Hungary", "IRL": "Republic of Ireland", "ISL": "Iceland",
+	"ISR": "Israel", "ITA": "Italy", "KAZ": "Kazakhstan", "KOS": "Kosovo",
+	"LIE": "Liechtenstein", "LTU": "Lithuania", "LUX": "Luxembourg",
+	"LVA": "Latvia", "MDA": "Moldova", "MKD": "North Macedonia",
+	"MLT": "Malta", "MNE": "Montenegro", "NED": "Netherlands",
+	"NIR": "Northern Ireland", "NOR": "Norway", "POL": "Poland",
+	"POR": "Portugal", "ROU": "Romania", "RUS": "Russia", "SCO": "Scotland",
+	"SMR": "San Marino", "SRB": "Serbia", "SUI": "Switzerland",
+	"SVK": "Slovakia", "SVN": "Slovenia", "SWE": "Sweden", "TUR": "Türkiye",
+	"UKR": "Ukraine", "WAL": "Wales",
+}
+
+// Diagnostic describes a problem found in a team file
+type Diagnostic struct {
+	File    string
+	Line    int
+	Message string
+}
+
+func (d Diagnostic) String() string {
+	file := d.File
+	if file == "" {
+		file = "<input>"
+	}
+	return fmt.Sprintf("%s:%d: %s", file, d.Line, d.Message)
+}
+
+// DiagnosticsError collects every problem found in a team file
+type DiagnosticsError struct {
+	Diagnostics []Diagnostic
+}
+
+func (e *DiagnosticsError) Error() string {
+	lines := make([]string, len(e.Diagnostics))
+	for i, d := range e.Diagnostics {
+		lines[i] = d.String()
+	}
+	return fmt.Sprintf("%v:\n%s", ErrInvalidTeams, strings.Join(lines, "\n"))
+}
+
+func (e *DiagnosticsError) Unwrap() error {
+	return ErrInvalidTeams
+}
+
+// TeamFile holds the teams read from a team file and the line each team
+// was read from
+type TeamFile struct {
+	Name  string
+	Teams []Team
+	Lines []int
+}
+
+// ReadTeams reads and validates a plain text team list
+func ReadTeams(r io.Reader) ([]Team, error) {
+	return LoadTeams(r, "", "text")
+}
+
+// ReadTeamsFile reads and validates the team file at path, choosing the
+// encoding from the file extension
+func ReadTeamsFile(path string) ([]Team, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return LoadTeams(file, path, Encoding(path))
+}
+
+// LoadTeams parses and validates a team file. The name is only used in
+// diagnostics.
+func LoadTeams(r io.Reader, name, encoding string) ([]Team, error) {
+	file, err := ParseTeams(r, name, encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := file.Validate(); err != nil {
+		return nil, err
+	}
+
+	return file.Teams, nil
+}
+
+// ParseTeams reads a team file in the given encoding. Surrounding
+// whitespace is trimmed from every field. Malformed rows are reported
+// together in a *DiagnosticsError.
+//
+// The text encoding holds one team per line: the name optionally followed
+// by comma separated country code, coefficient and pot, for example
+// "Porto,POR,77.0" or "Porto 2,POR,,D".
+//
+// The csv encoding starts with a header naming the columns, any of name,
+// code, country, city, stadium, coefficient and pot; name is required.
+//
+// The json encoding is an array of team objects with the same fields.
+func ParseTeams(r io.Reader, name, encoding string) (*TeamFile, error) {
+	p := &teamParser{file: &TeamFile{Name: name}}
+
+	var err error
+	switch encoding {
+	case "text":
+		err = p.text(r)
+	case "csv":
+		err = p.csv(r)
+	case "json":
+		err = p.json(r)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrEncoding, encoding)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	if len(p.diagnostics) > 0 {
+		return nil, &DiagnosticsError{Diagnostics: p.diagnostics}
+	}
+
+	return p.file, nil
+}
+
+// Validate reports duplicate names and codes, unknown countries and
+// negative coefficients
+func (f *TeamFile) Validate() error {
+	var diagnostics []Diagnostic
+	report := func(i int, format string, args ...any) {
+		diagnostics = append(diagnostics, Diagnostic{File: f.Name, Line: f.line(i), Message: fmt.Sprintf(format, args...)})
+	}
+
+	names := make(map[string]int)
+	codes := make(map[string]int)
+	for i, team := range f.Teams {
+		key := strings.ToLower(team.Name)
+		if first, ok := names[key]; ok {
+			report(i, "duplicate team %q, first defined on line %d", team.Name, f.line(first))
+		} else {
+			names[key] = i
+		}
+
+		if team.Code != "" {
+			if first, ok := codes[team.Code]; ok {
+				report(i, "duplicate code %q, first used by %s", team.Code, f.Teams[first].Name)
+			} else {
+				codes[team.Code] = i
+			}
+		}
+
+		if team.Country != "" {
+			if _, ok := Countries[team.Country]; !ok {
+				report(i, "unknown country %q for %s", team.Country, team.Name)
+			}
+		}
+
+		if team.Coefficient < 0 {
+			report(i, "negative coefficient %v for %s", team.Coefficient, team.Name)
+		}
+	}
+
+	if len(diagnostics) > 0 {
+		return &DiagnosticsError{Diagnostics: diagnostics}
+	}
+	return nil
+}
+
+func (f *TeamFile) line(i int) int {
+	if i < len(f.Lines) {
+		return f.Lines[i]
+	}
+	return 0
+}
+
+// teamParser collects teams and diagnostics while reading a team file
+type teamParser struct {
+	file        *TeamFile
+	diagnostics []Diagnostic
+}
+
+func (p *teamParser) add(team Team, line int) {
+	if team.Name == "" {
+		p.report(line, "missing team name")
+		return
+	}
+
+	p.file.Teams = append(p.file.Teams, team)
+	p.file.Lines = append(p.file.Lines, line)
+}
+
+func (p *teamParser) report(line int, format string, args ...any) {
+	p.diagnostics = append(p.diagnostics, Diagnostic{File: p.file.Name, Line: line, Message: fmt.Sprintf(format, args...)})
+}
+
+func (p *teamParser) coefficient(value string, line int) float64 {
+	if value == "" {
+		return 0
+	}
+
+	coefficient, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		p.report(line, "invalid coefficient %q", value)
+	}
+	return coefficient
+}
+
+func (p *teamParser) text(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		fields := strings.Split(line, ",")
+		if len(fields) > 4 {
+			p.report(n, "expected at most 4 fields, got %d", len(fields))
+			continue
+		}
+
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+		fields = append(fields, "", "", "")
+
+		p.add(Team{
+			Name:        fields[0],
+			Country:     fields[1],
+			Coefficient: p.coefficient(fields[2], n),
+			Pot:         fields[3],
+		}, n)
+	}
+
+	return scanner.Err()
+}
+
+// teamColumns lists the columns of the csv encoding
+var teamColumns = []string{"name", "code", "country", "city", "stadium", "coefficient", "pot"}
+
+func (p *teamParser) csv(r io.Reader) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	columns := make(map[string]int)
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(column))
+		if !contains(teamColumns, column) {
+			p.report(1, "unknown column %q, expected %v", column, teamColumns)
+			continue
+		}
+		columns[column] = i
+	}
+	if _, ok := columns["name"]; !ok {
+		p.report(1, "missing name column")
+		return nil
+	}
+
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			p.report(parseErr.Line, "%v", parseErr.Err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		line, _ := cr.FieldPos(0)
+		if len(record) != len(header) {
+			p.report(line, "expected %d fields, got %d", len(header), len(record))
+			continue
+		}
+
+		field := func(column string) string {
+			if i, ok := columns[column]; ok {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		p.add(Team{
+			Name:        field("name"),
+			Code:        field("code"),
+			Country:     field("country"),
+			City:        field("city"),
+			Stadium:     field("stadium"),
+			Coefficient: p.coefficient(field("coefficient"), line),
+			Pot:         field("pot"),
+		}, line)
+	}
+}
+
+func (p *teamParser) json(r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	lineAt := func(offset int64) int {
+		return bytes.Count(data[:offset], []byte("\n")) + 1
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+		p.report(1, "expected an array of teams")
+		return nil
+	}
+
+	for decoder.More() {
+		// skip the whitespace and comma before the team to find its line
+		offset := decoder.InputOffset()
+		for offset < int64(len(data)) && strings.ContainsRune(" \t\r\n,", rune(data[offset])) {
+			offset++
+		}
+		line := lineAt(offset)
+
+		var team Team
+		if err := decoder.Decode(&team); err != nil {
+			p.report(line, "invalid team: %v", err)
+			return nil
+		}
+
+		team.Name = strings.TrimSpace(team.Name)
+		team.Code = strings.TrimSpace(team.Code)
+		team.Country = strings.TrimSpace(team.Country)
+		team.City = strings.TrimSpace(team.City)
+		team.Stadium = strings.TrimSpace(team.Stadium)
+		team.Pot = strings.TrimSpace(team.Pot)
+		p.add(team, line)
+	}
+
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
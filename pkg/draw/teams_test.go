@@ -0,0 +1,102 @@
+package draw
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseTeamsText(t *testing.T) {
+	teams, err := ReadTeams(strings.NewReader("  AC Milan  ,ITA , 59\n"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if teams[0].Name != "AC Milan" || teams[0].Country != "ITA" || teams[0].Coefficient != 59 {
+		t.Errorf("Expected trimmed fields, got %+v", teams[0])
+	}
+}
+
+func TestParseTeamsCSV(t *testing.T) {
+	input := `name, code, country, city, stadium, coefficient
+Real Madrid, RMA, ESP, Madrid, Santiago Bernabéu, 136
+"Porto", POR, POR, Porto, "Estádio do Dragão", 77
+`
+	teams, err := LoadTeams(strings.NewReader(input), "teams.csv", "csv")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	want := Team{Name: "Porto", Code: "POR", Country: "POR", City: "Porto", Stadium: "Estádio do Dragão", Coefficient: 77}
+	if len(teams) != 2 || teams[1] != want {
+		t.Errorf("Expected %+v, got %+v", want, teams)
+	}
+}
+
+func TestParseTeamsJSON(t *testing.T) {
+	input := `[
+  {"name": "Real Madrid", "code": "RMA", "country": "ESP"},
+  {"name": "Real Madrid", "code": "RM2", "country": "ESP"}
+]`
+	_, err := LoadTeams(strings.NewReader(input), "teams.json", "json")
+
+	var diagnostics *DiagnosticsError
+	if !errors.As(err, &diagnostics) {
+		t.Fatalf("Expected DiagnosticsError, got %v", err)
+	}
+
+	if got := diagnostics.Diagnostics[0].String(); got != `teams.json:3: duplicate team "Real Madrid", first defined on line 2` {
+		t.Errorf("Unexpected diagnostic: %s", got)
+	}
+}
+
+func TestValidateTeams(t *testing.T) {
+	input := `name,code,country,coefficient
+Real Madrid,RMA,ESP,136
+Barcelona,RMA,EPS,91
+Porto,POR,POR,abc
+,BEN,POR,79
+Benfica,BEN,POR
+`
+	_, err := ParseTeams(strings.NewReader(input), "teams.csv", "csv")
+
+	var diagnostics *DiagnosticsError
+	if !errors.As(err, &diagnostics) {
+		t.Fatalf("Expected DiagnosticsError, got %v", err)
+	}
+
+	want := []string{
+		`teams.csv:4: invalid coefficient "abc"`,
+		`teams.csv:5: missing team name`,
+		`teams.csv:6: expected 4 fields, got 3`,
+	}
+	checkDiagnostics(t, diagnostics, want)
+
+	file, err := ParseTeams(strings.NewReader(strings.Join(strings.Split(input, "\n")[:3], "\n")), "teams.csv", "csv")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !errors.As(file.Validate(), &diagnostics) {
+		t.Fatal("Expected validation to fail")
+	}
+
+	want = []string{
+		`teams.csv:3: duplicate code "RMA", first used by Real Madrid`,
+		`teams.csv:3: unknown country "EPS" for Barcelona`,
+	}
+	checkDiagnostics(t, diagnostics, want)
+}
+
+func checkDiagnostics(t *testing.T, err *DiagnosticsError, want []string) {
+	t.Helper()
+
+	if len(err.Diagnostics) != len(want) {
+		t.Fatalf("Expected %d diagnostics, got %v", len(want), err)
+	}
+	for i, d := range err.Diagnostics {
+		if d.String() != want[i] {
+			t.Errorf("Expected %q, got %q", want[i], d.String())
+		}
+	}
+}
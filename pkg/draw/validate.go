@@ -0,0 +1,274 @@
+package draw
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Rules checked by Draw.Validate
+const (
+	// RuleFormat is broken when the format itself cannot produce a draw
+	RuleFormat = "format"
+	// RulePots is broken by pots of the wrong number or size
+	RulePots = "pots"
+	// RuleFixture is broken by matches with unknown teams
+	RuleFixture = "fixture"
+	// RuleRepeat is broken when two teams meet more than once
+	RuleRepeat = "repeat"
+	// RuleOpponents is broken when a team does not get the expected number
+	// of opponents from every pot
+	RuleOpponents = "opponents"
+	// RuleVenues is broken when home and away matches are unbalanced, overall
+	// or, with two matches per pot, against any single pot
+	RuleVenues = "venues"
+	// RuleCountry is broken by same country matches and by more than
+	// MaxOpponentsPerCountry opponents from one country
+	RuleCountry = "country"
+	// RuleMatchday is broken by unscheduled matches and by teams playing
+	// twice on a matchday
+	RuleMatchday = "matchday"
+)
+
+// Violation describes one rule broken by a draw
+type Violation struct {
+	Rule string
+	// Team is the team the violation is about, empty when it concerns the
+	// draw as a whole
+	Team    string
+	Message string
+}
+
+func (v Violation) String() string {
+	if v.Team == "" {
+		return fmt.Sprintf("%s: %s", v.Rule, v.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", v.Rule, v.Team, v.Message)
+}
+
+// ValidationError collects every rule broken by a draw
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		lines[i] = v.String()
+	}
+	return fmt.Sprintf("%v:\n%s", ErrInvalidDraw, strings.Join(lines, "\n"))
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidDraw
+}
+
+// Validate checks the draw against the rules of its format: pot sizes,
+// the number of opponents from every pot, home and away balance, repeated
+// matches, country rules for teams with a known country and, once any
+// match has a matchday, the schedule. Every broken rule is reported in a
+// *ValidationError. Validate does not rely on how the draw was made, so
+// it also checks hand-made draws.
+func (d *Draw) Validate() error {
+	v := &validator{draw: d, format: d.Format}
+	v.validate()
+
+	if len(v.violations) > 0 {
+		return &ValidationError{Violations: v.violations}
+	}
+	return nil
+}
+
+// record counts the matches of a single team
+type record struct {
+	home      int
+	away      int
+	perPot    []int
+	homePot   []int
+	countries map[string]int
+}
+
+type validator struct {
+	draw       *Draw
+	format     Format
+	violations []Violation
+
+	// pot is the index of the pot of every team
+	pot     map[string]int
+	teams   []Team
+	records map[string]*record
+}
+
+func (v *validator) report(rule, team, format string, args ...any) {
+	v.violations = append(v.violations, Violation{Rule: rule, Team: team, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) validate() {
+	if err := v.format.Validate(); err != nil {
+		// nothing else can be checked without a valid format
+		v.report(RuleFormat, "", "%v", err)
+		return
+	}
+
+	v.pots()
+	v.fixtures()
+	v.balance()
+	v.schedule()
+}
+
+func (v *validator) pots() {
+	f := v.format
+	if len(v.draw.Pots) != f.Pots {
+		v.report(RulePots, "", "expected %d pots, got %d", f.Pots, len(v.draw.Pots))
+	}
+
+	v.pot = make(map[string]int)
+	v.records = make(map[string]*record)
+	for i, pot := range v.draw.Pots {
+		if len(pot.Teams) != f.PotSize() {
+			v.report(RulePots, "", "pot %s has %d teams, expected %d", pot.Name, len(pot.Teams), f.PotSize())
+		}
+
+		for _, team := range pot.Teams {
+			if first, ok := v.pot[team.Name]; ok {
+				v.report(RulePots, team.Name, "in pot %s and pot %s", v.draw.Pots[first].Name, pot.Name)
+				continue
+			}
+			v.pot[team.Name] = i
+			v.teams = append(v.teams, team)
+			v.records[team.Name] = &record{
+				perPot:    make([]int, len(v.draw.Pots)),
+				homePot:   make([]int, len(v.draw.Pots)),
+				countries: make(map[string]int),
+			}
+		}
+	}
+}
+
+func (v *validator) fixtures() {
+	country := make(map[string]string, len(v.teams))
+	for _, team := range v.teams {
+		country[team.Name] = team.Country
+	}
+
+	seen := make(map[[2]string]Fixture)
+	for _, fixture := range v.draw.Fixtures {
+		known := true
+		for _, name := range []string{fixture.Home, fixture.Away} {
+			if _, ok := v.pot[name]; !ok {
+				v.report(RuleFixture, "", "%q in match %q is not in any pot", name, fixture)
+				known = false
+			}
+		}
+		if !known {
+			continue
+		}
+		if fixture.Home == fixture.Away {
+			v.report(RuleFixture, fixture.Home, "plays itself")
+			continue
+		}
+
+		key := [2]string{fixture.Home, fixture.Away}
+		if key[0] > key[1] {
+			key[0], key[1] = key[1], key[0]
+		}
+		if first, ok := seen[key]; ok {
+			v.report(RuleRepeat, "", "%q repeats %q", fixture, first)
+		} else {
+			seen[key] = fixture
+		}
+
+		home, away := v.records[fixture.Home], v.records[fixture.Away]
+		home.home++
+		away.away++
+		home.perPot[v.pot[fixture.Away]]++
+		home.homePot[v.pot[fixture.Away]]++
+		away.perPot[v.pot[fixture.Home]]++
+
+		a, b := country[fixture.Home], country[fixture.Away]
+		if a != "" && a == b {
+			v.report(RuleCountry, "", "%q is between two %s teams", fixture, a)
+		}
+		if b != "" {
+			home.countries[b]++
+		}
+		if a != "" {
+			away.countries[a]++
+		}
+	}
+}
+
+func (v *validator) balance() {
+	f := v.format
+	venues := f.MatchesPerTeam() / 2
+
+	for _, team := range v.teams {
+		r := v.records[team.Name]
+
+		if played := r.home + r.away; played != f.MatchesPerTeam() {
+			v.report(RuleOpponents, team.Name, "plays %d matches, expected %d", played, f.MatchesPerTeam())
+		}
+		for i, n := range r.perPot {
+			if n != f.MatchesPerPot {
+				v.report(RuleOpponents, team.Name, "has %d opponents from pot %s, expected %d", n, v.draw.Pots[i].Name, f.MatchesPerPot)
+			}
+		}
+
+		if r.home != venues || r.away != venues {
+			v.report(RuleVenues, team.Name, "has %d home and %d away matches, expected %d of each", r.home, r.away, venues)
+		}
+		if f.MatchesPerPot == 2 {
+			for i, n := range r.perPot {
+				if home := r.homePot[i]; n == 2 && home != 1 {
+					v.report(RuleVenues, team.Name, "has %d home and %d away matches against pot %s, expected one of each", home, n-home, v.draw.Pots[i].Name)
+				}
+			}
+		}
+
+		countries := make([]string, 0, len(r.countries))
+		for country := range r.countries {
+			countries = append(countries, country)
+		}
+		sort.Strings(countries)
+		for _, country := range countries {
+			if n := r.countries[country]; n > MaxOpponentsPerCountry {
+				v.report(RuleCountry, team.Name, "has %d opponents from %s, at most %d allowed", n, country, MaxOpponentsPerCountry)
+			}
+		}
+	}
+}
+
+func (v *validator) schedule() {
+	scheduled := false
+	for _, fixture := range v.draw.Fixtures {
+		if fixture.Matchday != 0 {
+			scheduled = true
+			break
+		}
+	}
+	if !scheduled {
+		return
+	}
+
+	matchdays := v.format.MatchesPerTeam()
+	playing := make(map[string]map[int]bool)
+	for _, fixture := range v.draw.Fixtures {
+		switch {
+		case fixture.Matchday <= 0:
+			v.report(RuleMatchday, "", "%q has no matchday", fixture)
+			continue
+		case fixture.Matchday > matchdays:
+			v.report(RuleMatchday, "", "%q is on matchday %d, the format has %d", fixture, fixture.Matchday, matchdays)
+		}
+
+		for _, name := range []string{fixture.Home, fixture.Away} {
+			if playing[name] == nil {
+				playing[name] = make(map[int]bool)
+			}
+			if playing[name][fixture.Matchday] {
+				v.report(RuleMatchday, name, "plays twice on matchday %d", fixture.Matchday)
+			}
+			playing[name][fixture.Matchday] = true
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package draw
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	teams, err := ReadTeamsFile("../../example/teams.txt")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for _, format := range []Format{ChampionsLeague, ConferenceLeague} {
+		d, err := NewEngine(&Config{Seed: 3, Format: format}).Run(teams)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if err := d.Validate(); err != nil {
+			t.Errorf("Expected %s draw to be valid, got %v", format.Name, err)
+		}
+
+		if err := Schedule(d); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if err := d.Validate(); err != nil {
+			t.Errorf("Expected scheduled %s draw to be valid, got %v", format.Name, err)
+		}
+	}
+}
+
+func TestValidateViolations(t *testing.T) {
+	teams, err := ReadTeamsFile("../../example/teams.txt")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	d, err := NewEngine(&Config{Seed: 3}).Run(teams)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := Schedule(d); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// replace the first match with a repeat of the second one played the
+	// other way round
+	first, second := d.Fixtures[0], d.Fixtures[1]
+	d.Fixtures[0] = Fixture{Home: second.Away, Away: second.Home, Matchday: first.Matchday}
+
+	err = d.Validate()
+	if !errors.Is(err, ErrInvalidDraw) {
+		t.Fatalf("Expected ErrInvalidDraw, got %v", err)
+	}
+
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Expected *ValidationError, got %T", err)
+	}
+
+	rules := make(map[string]bool)
+	for _, v := range verr.Violations {
+		rules[v.Rule] = true
+	}
+	for _, rule := range []string{RuleRepeat, RuleOpponents, RuleVenues, RuleMatchday} {
+		if !rules[rule] {
+			t.Errorf("Expected a %s violation, got %v", rule, verr.Violations)
+		}
+	}
+}
+
+func TestValidateHandMade(t *testing.T) {
+	d := &Draw{
+		Format: Format{Name: "tiny", Teams: 4, Pots: 2, MatchesPerPot: 1},
+		Pots: []Pot{
+			{Name: "A", Teams: []Team{{Name: "a1", Country: "ESP"}, {Name: "a2", Country: "ESP"}}},
+			{Name: "B", Teams: []Team{{Name: "b1"}, {Name: "b2"}, {Name: "b3"}}},
+		},
+		Fixtures: []Fixture{{Home: "a1", Away: "a2"}, {Home: "a1", Away: "b1"}, {Home: "b2", Away: "x"}},
+	}
+
+	var verr *ValidationError
+	if !errors.As(d.Validate(), &verr) {
+		t.Fatal("Expected *ValidationError")
+	}
+
+	want := []string{
+		`pots: pot B has 3 teams, expected 2`,
+		`fixture: "x" in match "b2 - x" is not in any pot`,
+		`country: "a1 - a2" is between two ESP teams`,
+		`venues: a1: has 2 home and 0 away matches, expected 1 of each`,
+		`venues: a2: has 0 home and 1 away matches, expected 1 of each`,
+		`opponents: a2: plays 1 matches, expected 2`,
+	}
+	got := make(map[string]bool)
+	for _, v := range verr.Violations {
+		got[v.String()] = true
+	}
+	for _, w := range want {
+		if !got[w] {
+			t.Errorf("Expected violation %q, got %v", w, verr.Violations)
+		}
+	}
+}
+
+func TestValidatePotVenues(t *testing.T) {
+	teams, err := ReadTeamsFile("../../example/teams.txt")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	d, err := NewEngine(&Config{Seed: 3}).Run(teams)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// turning one of the first team's away games into a home game gives it
+	// two home games against that pot
+	f := d.Fixtures[0]
+	var other int
+	for i, g := range d.Fixtures {
+		if g.Away == f.Home && g.Home != f.Away {
+			other = i
+			break
+		}
+	}
+	d.Fixtures[other].Home, d.Fixtures[other].Away = d.Fixtures[other].Away, d.Fixtures[other].Home
+
+	var verr *ValidationError
+	if !errors.As(d.Validate(), &verr) {
+		t.Fatal("Expected *ValidationError")
+	}
+
+	found := false
+	for _, v := range verr.Violations {
+		if v.Rule == RuleVenues && v.Team == f.Home && strings.Contains(v.Message, "against pot") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected a pot venue violation for %s, got %v", f.Home, verr.Violations)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/patraden/code-with-kids/pkg/http/server"
+	"github.com/patraden/code-with-kids/pkg/store"
 )
 
 // Example types for future use
@@ -15,6 +16,12 @@ import (
 // }
 
 func main() {
+	// Keep draws in the same directory as 'draw draw -store draws'
+	drawStore, err := store.NewFileStore("draws")
+	if err != nil {
+		log.Fatalf("Draw store error: %v", err)
+	}
+
 	// Create server with custom configuration
 	config := &server.Config{
 		Port:         8888,
@@ -22,6 +29,7 @@ func main() {
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
 		Host:         "localhost",
+		DrawStore:    drawStore,
 	}
 
 	srv := server.New(config)
@@ -29,6 +37,10 @@ func main() {
 	// Add health check routes
 	srv.AddHealthRoutes()
 
+	// Add the draw API and live draw sessions
+	srv.AddDrawRoutes()
+	srv.AddLiveRoutes()
+
 	// Add custom routes (if needed in the future)
 	// srv.AddGET("/api/example", exampleHandler)
 
@@ -47,6 +59,17 @@ func main() {
 	log.Println("  GET  /ready - Readiness check")
 	log.Println("  GET  /live - Liveness check")
 	log.Println("  GET  /info - Server info")
+	log.Println("  POST /api/draws - Run a draw")
+	log.Println("  GET  /api/draws - List stored draws")
+	log.Println("  GET  /api/draws/{id} - Draw details")
+	log.Println("  DELETE /api/draws/{id} - Delete a draw")
+	log.Println("  GET  /api/draws/{id}/history - Draw history")
+	log.Println("  GET  /api/draws/{id}/fixtures - Draw fixtures")
+	log.Println("  GET  /api/draws/{id}/standings - League phase table")
+	log.Println("  POST /api/draws/{id}/results - Report match results")
+	log.Println("  POST /api/live - Present a stored draw live")
+	log.Println("  GET  /api/live/{id}/events - Live draw event stream")
+	log.Println("  GET  /static/live.html - Live draw page")
 	log.Println("  GET  /static/* - Static files")
 
 	// Start server with graceful shutdown
@@ -0,0 +1,316 @@
+package draws
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/patraden/code-with-kids/pkg/draw"
+	"github.com/patraden/code-with-kids/pkg/http/server/internal/request"
+	"github.com/patraden/code-with-kids/pkg/http/server/internal/response"
+	"github.com/patraden/code-with-kids/pkg/league"
+	"github.com/patraden/code-with-kids/pkg/store"
+)
+
+// maxBodySize limits request bodies, enough for a few hundred teams or
+// results
+const maxBodySize = 1 << 20
+
+// CreateRequest is the body of a new draw request
+type CreateRequest struct {
+	// Teams is an array of team objects as in a JSON team file
+	Teams json.RawMessage `json:"teams"`
+	// Profile names the competition format, draw.ChampionsLeague when empty
+	Profile string `json:"profile,omitempty"`
+	// Seed makes the draw reproducible, random when nil
+	Seed *int64 `json:"seed,omitempty"`
+	// Schedule splits the fixtures into matchdays
+	Schedule bool `json:"schedule,omitempty"`
+}
+
+// View is the JSON representation of a stored draw
+type View struct {
+	ID        string         `json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	Seed      int64          `json:"seed"`
+	Version   string         `json:"algorithm_version"`
+	Format    draw.Format    `json:"format"`
+	Matchdays int            `json:"matchdays"`
+	Pots      []draw.Pot     `json:"pots"`
+	Fixtures  []draw.Fixture `json:"fixtures"`
+	Balance   []draw.Balance `json:"balance"`
+	Results   int            `json:"results"`
+}
+
+func view(rec *store.Record) View {
+	d := rec.Draw
+	return View{
+		ID:        rec.ID,
+		CreatedAt: rec.CreatedAt,
+		UpdatedAt: rec.UpdatedAt,
+		Seed:      d.Seed,
+		Version:   d.Version,
+		Format:    d.Format,
+		Matchdays: len(d.Matchdays()),
+		Pots:      d.Pots,
+		Fixtures:  d.Fixtures,
+		Balance:   d.Balances(),
+		Results:   len(rec.Results),
+	}
+}
+
+// Summary is the JSON representation of a draw in a list
+type Summary struct {
+	ID        string      `json:"id"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+	Seed      int64       `json:"seed"`
+	Format    draw.Format `json:"format"`
+	Results   int         `json:"results"`
+}
+
+// Handler serves the draw API from a draw store
+type Handler struct {
+	store store.DrawStore
+	// mu serialises result updates, which read and then replace a record
+	mu sync.Mutex
+}
+
+// NewHandler creates a handler keeping draws in the store
+func NewHandler(s store.DrawStore) *Handler {
+	return &Handler{store: s}
+}
+
+// Create runs a draw of the posted teams
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+
+	var req CreateRequest
+	if err := request.ParseJSON(r, &req); err != nil {
+		response.BadRequest(w, fmt.Sprintf("invalid JSON: %v", err))
+		return
+	}
+	if len(req.Teams) == 0 {
+		response.BadRequest(w, "teams are required")
+		return
+	}
+
+	config := draw.DefaultConfig()
+	if req.Profile != "" {
+		format, ok := draw.Profile(req.Profile)
+		if !ok {
+			response.BadRequest(w, fmt.Sprintf("unknown profile %q, expected one of %v", req.Profile, draw.Profiles()))
+			return
+		}
+		config.Format = format
+	}
+	if req.Seed != nil {
+		config.Seed = *req.Seed
+	}
+
+	teams, err := draw.LoadTeams(bytes.NewReader(req.Teams), "teams", "json")
+	if err != nil {
+		drawError(w, err)
+		return
+	}
+
+	d, err := draw.NewEngine(config).Run(teams)
+	if err != nil {
+		drawError(w, err)
+		return
+	}
+	if req.Schedule {
+		if err := draw.Schedule(d); err != nil {
+			drawError(w, err)
+			return
+		}
+	}
+
+	rec, err := h.store.Create(d)
+	if err != nil {
+		response.InternalServerError(w, err.Error())
+		return
+	}
+
+	w.Header().Set("Location", "/api/draws/"+rec.ID)
+	response.Created(w, view(rec), "Draw created")
+}
+
+// List returns a summary of every stored draw, oldest first
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	records, err := h.store.List()
+	if err != nil {
+		response.InternalServerError(w, err.Error())
+		return
+	}
+
+	summaries := make([]Summary, len(records))
+	for i, rec := range records {
+		summaries[i] = Summary{
+			ID:        rec.ID,
+			CreatedAt: rec.CreatedAt,
+			UpdatedAt: rec.UpdatedAt,
+			Seed:      rec.Draw.Seed,
+			Format:    rec.Draw.Format,
+			Results:   len(rec.Results),
+		}
+	}
+
+	response.Success(w, summaries, "")
+}
+
+// Get returns a draw with its pots, fixtures and home and away balance
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	rec, ok := h.record(w, r)
+	if !ok {
+		return
+	}
+
+	response.Success(w, view(rec), "")
+}
+
+// Delete removes a draw
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.store.Delete(request.GetPathParam(r, "id")); err != nil {
+		storeError(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// History returns the changes made to a draw, including deleted draws
+func (h *Handler) History(w http.ResponseWriter, r *http.Request) {
+	id := request.GetPathParam(r, "id")
+	changes, err := h.store.History(id)
+	if err != nil {
+		response.InternalServerError(w, err.Error())
+		return
+	}
+	if len(changes) == 0 {
+		response.NotFound(w, fmt.Sprintf("draw %q not found", id))
+		return
+	}
+
+	response.Success(w, changes, "")
+}
+
+// Fixtures returns the fixtures of a draw, optionally only those of one
+// team or matchday
+func (h *Handler) Fixtures(w http.ResponseWriter, r *http.Request) {
+	rec, ok := h.record(w, r)
+	if !ok {
+		return
+	}
+
+	team := request.GetQueryParam(r, "team")
+	matchday, err := request.GetQueryParamInt(r, "matchday")
+	if err != nil {
+		response.BadRequest(w, "matchday must be a number")
+		return
+	}
+
+	fixtures := []draw.Fixture{}
+	for _, f := range rec.Draw.Fixtures {
+		if team != "" && f.Home != team && f.Away != team {
+			continue
+		}
+		if matchday != 0 && f.Matchday != matchday {
+			continue
+		}
+		fixtures = append(fixtures, f)
+	}
+
+	response.Success(w, fixtures, "")
+}
+
+// Standings returns the league phase table from the results reported so far
+func (h *Handler) Standings(w http.ResponseWriter, r *http.Request) {
+	rec, ok := h.record(w, r)
+	if !ok {
+		return
+	}
+
+	standings, err := league.NewStandings(rec.Draw, rec.Results)
+	if err != nil {
+		response.InternalServerError(w, err.Error())
+		return
+	}
+
+	response.Success(w, standings, "")
+}
+
+// AddResults records the posted match results of a draw. Results are
+// checked against the fixtures and earlier results before any is stored.
+func (h *Handler) AddResults(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+	var matches []league.Match
+	if err := request.ParseJSON(r, &matches); err != nil {
+		response.BadRequest(w, fmt.Sprintf("invalid JSON: %v", err))
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	rec, ok := h.record(w, r)
+	if !ok {
+		return
+	}
+
+	rec.Results = append(rec.Results, matches...)
+	standings, err := league.NewStandings(rec.Draw, rec.Results)
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+	if err := h.store.Update(rec); err != nil {
+		storeError(w, err)
+		return
+	}
+
+	response.Success(w, standings, strconv.Itoa(len(matches))+" results added")
+}
+
+// record looks up the draw named in the path, answering 404 when unknown
+func (h *Handler) record(w http.ResponseWriter, r *http.Request) (*store.Record, bool) {
+	rec, err := h.store.Get(request.GetPathParam(r, "id"))
+	if err != nil {
+		storeError(w, err)
+		return nil, false
+	}
+	return rec, true
+}
+
+// storeError answers 404 for unknown draws and 500 otherwise
+func storeError(w http.ResponseWriter, err error) {
+	if errors.Is(err, store.ErrNotFound) {
+		response.NotFound(w, err.Error())
+		return
+	}
+	response.InternalServerError(w, err.Error())
+}
+
+// drawError answers 400 for invalid teams and 422 for teams that cannot
+// be drawn
+func drawError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, draw.ErrInvalidTeams),
+		errors.Is(err, draw.ErrTeamCount),
+		errors.Is(err, draw.ErrPotSize),
+		errors.Is(err, draw.ErrInvalidPot),
+		errors.Is(err, draw.ErrDuplicateTeam),
+		errors.Is(err, draw.ErrInvalidFormat):
+		response.BadRequest(w, err.Error())
+	case errors.Is(err, draw.ErrUnsatisfiable), errors.Is(err, draw.ErrUnschedulable):
+		response.Error(w, http.StatusUnprocessableEntity, err.Error())
+	default:
+		response.InternalServerError(w, err.Error())
+	}
+}
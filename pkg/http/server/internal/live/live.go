@@ -0,0 +1,265 @@
+package live
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/patraden/code-with-kids/pkg/draw"
+	"github.com/patraden/code-with-kids/pkg/http/server/internal/request"
+	"github.com/patraden/code-with-kids/pkg/http/server/internal/response"
+	"github.com/patraden/code-with-kids/pkg/store"
+)
+
+const (
+	// DefaultDelay is the pause between two steps of the ceremony when the
+	// session does not set one
+	DefaultDelay = 2 * time.Second
+	// DefaultRetention is how long a finished session stays available when
+	// the hub does not set it
+	DefaultRetention = 10 * time.Minute
+	// keepAlive is how often an idle stream sends a comment, so proxies do
+	// not close it
+	keepAlive = 15 * time.Second
+	// maxBodySize limits request bodies, which only name a draw
+	maxBodySize = 1 << 10
+)
+
+// CreateRequest is the body of a new live session request
+type CreateRequest struct {
+	// DrawID names the stored draw to present
+	DrawID string `json:"draw_id"`
+	// DelayMS is the pause between steps in milliseconds, DefaultDelay when
+	// nil
+	DelayMS *int `json:"delay_ms,omitempty"`
+}
+
+// Status is the JSON representation of a session
+type Status struct {
+	ID       string `json:"id"`
+	DrawID   string `json:"draw_id"`
+	Events   int    `json:"events"`
+	Viewers  int    `json:"viewers"`
+	Paused   bool   `json:"paused"`
+	Finished bool   `json:"finished"`
+}
+
+// Hub hosts live draw sessions and streams them to their viewers. Sessions
+// are removed once they have been finished for the retention period.
+type Hub struct {
+	store     store.DrawStore
+	retention time.Duration
+
+	mu       sync.Mutex
+	sessions map[string]*session
+	next     int
+}
+
+// NewHub creates a hub presenting draws from the store and keeping finished
+// sessions for the retention period, DefaultRetention when zero
+func NewHub(s store.DrawStore, retention time.Duration) *Hub {
+	if retention <= 0 {
+		retention = DefaultRetention
+	}
+	return &Hub{store: s, retention: retention, sessions: make(map[string]*session)}
+}
+
+// Create starts presenting a stored draw
+func (h *Hub) Create(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+
+	var req CreateRequest
+	if err := request.ParseJSON(r, &req); err != nil {
+		response.BadRequest(w, fmt.Sprintf("invalid JSON: %v", err))
+		return
+	}
+	if req.DrawID == "" {
+		response.BadRequest(w, "draw_id is required")
+		return
+	}
+
+	delay := DefaultDelay
+	if req.DelayMS != nil {
+		if *req.DelayMS < 0 {
+			response.BadRequest(w, "delay_ms must not be negative")
+			return
+		}
+		delay = time.Duration(*req.DelayMS) * time.Millisecond
+	}
+
+	rec, err := h.store.Get(req.DrawID)
+	if errors.Is(err, store.ErrNotFound) {
+		response.NotFound(w, err.Error())
+		return
+	}
+	if err != nil {
+		response.InternalServerError(w, err.Error())
+		return
+	}
+
+	h.mu.Lock()
+	h.next++
+	s := newSession(strconv.Itoa(h.next), rec.ID, draw.NewPlayer(draw.Events(rec.Draw, nil), delay))
+	h.sessions[s.id] = s
+	h.mu.Unlock()
+
+	go func() {
+		s.run()
+		time.AfterFunc(h.retention, func() { h.evict(s) })
+	}()
+
+	w.Header().Set("Location", "/api/live/"+s.id)
+	response.Created(w, s.status(), "Live session started")
+}
+
+// Get returns the state of a session
+func (h *Hub) Get(w http.ResponseWriter, r *http.Request) {
+	s, ok := h.session(w, r)
+	if !ok {
+		return
+	}
+
+	response.Success(w, s.status(), "")
+}
+
+// Pause holds the ceremony after the current step
+func (h *Hub) Pause(w http.ResponseWriter, r *http.Request) {
+	s, ok := h.session(w, r)
+	if !ok {
+		return
+	}
+
+	s.player.Pause()
+	response.Success(w, s.status(), "Paused")
+}
+
+// Resume continues a paused ceremony
+func (h *Hub) Resume(w http.ResponseWriter, r *http.Request) {
+	s, ok := h.session(w, r)
+	if !ok {
+		return
+	}
+
+	s.player.Resume()
+	response.Success(w, s.status(), "Resumed")
+}
+
+// Delete stops a session and disconnects its viewers
+func (h *Hub) Delete(w http.ResponseWriter, r *http.Request) {
+	id := request.GetPathParam(r, "id")
+
+	h.mu.Lock()
+	s, ok := h.sessions[id]
+	delete(h.sessions, id)
+	h.mu.Unlock()
+	if !ok {
+		response.NotFound(w, fmt.Sprintf("live session %q not found", id))
+		return
+	}
+
+	s.stop()
+	response.NoContent(w)
+}
+
+// Events streams the session as server-sent events. Every event carries
+// its sequence number as ID, so viewers joining late or reconnecting with
+// Last-Event-ID first receive the steps they missed. The stream ends after
+// the finished event.
+func (h *Hub) Events(w http.ResponseWriter, r *http.Request) {
+	s, ok := h.session(w, r)
+	if !ok {
+		return
+	}
+
+	next := 0
+	if last := request.GetHeader(r, "Last-Event-ID"); last != "" {
+		seq, err := strconv.Atoi(last)
+		if err != nil || seq < 0 {
+			response.BadRequest(w, "invalid Last-Event-ID")
+			return
+		}
+		next = seq
+	}
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	if err := rc.Flush(); err != nil {
+		response.InternalServerError(w, "streaming is not supported")
+		return
+	}
+	// the stream outlives the server write timeout
+	rc.SetWriteDeadline(time.Time{})
+
+	s.join()
+	defer s.leave()
+
+	ticker := time.NewTicker(keepAlive)
+	defer ticker.Stop()
+
+	for {
+		events, changed, done := s.since(next)
+		for _, e := range events {
+			if err := writeEvent(w, e); err != nil {
+				return
+			}
+		}
+		next += len(events)
+		if err := rc.Flush(); err != nil {
+			return
+		}
+		if done {
+			return
+		}
+
+		select {
+		case <-changed:
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// evict removes a finished session unless it was already deleted
+func (h *Hub) evict(s *session) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.sessions[s.id] == s {
+		delete(h.sessions, s.id)
+	}
+}
+
+// session looks up the session named in the path, answering 404 when
+// unknown
+func (h *Hub) session(w http.ResponseWriter, r *http.Request) (*session, bool) {
+	id := request.GetPathParam(r, "id")
+
+	h.mu.Lock()
+	s, ok := h.sessions[id]
+	h.mu.Unlock()
+	if !ok {
+		response.NotFound(w, fmt.Sprintf("live session %q not found", id))
+	}
+	return s, ok
+}
+
+// writeEvent writes a ceremony event in the server-sent events format
+func writeEvent(w http.ResponseWriter, e draw.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.Seq, e.Kind, data)
+	return err
+}
@@ -0,0 +1,103 @@
+package live
+
+import (
+	"context"
+	"sync"
+
+	"github.com/patraden/code-with-kids/pkg/draw"
+)
+
+// session plays the ceremony of a draw once and keeps every event, so any
+// number of viewers can follow it from the start
+type session struct {
+	id     string
+	drawID string
+	player *draw.Player
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	history []draw.Event
+	// changed is closed and replaced whenever an event is added or the
+	// session ends, waking every viewer
+	changed chan struct{}
+	done    bool
+	viewers int
+}
+
+func newSession(id, drawID string, player *draw.Player) *session {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &session{
+		id:      id,
+		drawID:  drawID,
+		player:  player,
+		ctx:     ctx,
+		cancel:  cancel,
+		changed: make(chan struct{}),
+	}
+}
+
+// run plays the ceremony until it ends or the session is stopped
+func (s *session) run() {
+	for e := range s.player.Play(s.ctx) {
+		s.mu.Lock()
+		s.history = append(s.history, e)
+		s.notify()
+		s.mu.Unlock()
+	}
+
+	s.mu.Lock()
+	s.done = true
+	s.notify()
+	s.mu.Unlock()
+}
+
+// stop ends the ceremony early
+func (s *session) stop() {
+	s.cancel()
+}
+
+// notify wakes the viewers; the caller holds s.mu
+func (s *session) notify() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// since returns the events after the first n, a channel closed on the next
+// change and whether the session has ended
+func (s *session) since(n int) ([]draw.Event, <-chan struct{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var events []draw.Event
+	if n < len(s.history) {
+		events = append(events, s.history[n:]...)
+	}
+	return events, s.changed, s.done
+}
+
+func (s *session) join() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.viewers++
+}
+
+func (s *session) leave() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.viewers--
+}
+
+func (s *session) status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return Status{
+		ID:       s.id,
+		DrawID:   s.drawID,
+		Events:   len(s.history),
+		Viewers:  s.viewers,
+		Paused:   s.player.Paused(),
+		Finished: s.done,
+	}
+}
@@ -1,69 +1,483 @@
 package middleware
 
 import (
-	"log"
+	"bufio"
+	crand "crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"math/rand/v2"
+	"net"
 	"net/http"
 	"runtime/debug"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/patraden/code-with-kids/pkg/http/server/internal/request"
 )
 
-// Logging logs HTTP requests
-func Logging(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
+// RequestIDHeader carries the ID of a request between services
+const RequestIDHeader = "X-Request-ID"
 
-		// Create a response writer wrapper to capture status code
-		wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+// maxRequestIDLength bounds request IDs propagated from clients
+const maxRequestIDLength = 128
 
-		next.ServeHTTP(wrapped, r)
+// RequestID gives every request an ID, taken from the X-Request-ID header
+// when the client sent a usable one and generated otherwise. The ID is put
+// into the request context and echoed in the response header.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
 
-		duration := time.Since(start)
-		log.Printf("%s %s %d %v", r.Method, r.URL.Path, wrapped.statusCode, duration)
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(request.WithRequestID(r.Context(), id)))
 	})
 }
 
-// CORS adds CORS headers
-func CORS(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+// validRequestID accepts short IDs of printable ASCII, so client supplied
+// IDs cannot forge log lines
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	crand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Access log fields
+const (
+	FieldMethod    = "method"
+	FieldPath      = "path"
+	FieldQuery     = "query"
+	FieldStatus    = "status"
+	FieldBytes     = "bytes"
+	FieldDuration  = "duration"
+	FieldRequestID = "request_id"
+	FieldClientIP  = "client_ip"
+	FieldUserAgent = "user_agent"
+	FieldReferer   = "referer"
+	FieldProto     = "proto"
+)
+
+// DefaultFields are the access log fields used when none are configured
+var DefaultFields = []string{
+	FieldMethod, FieldPath, FieldStatus, FieldBytes, FieldDuration,
+	FieldRequestID, FieldClientIP, FieldUserAgent,
+}
+
+// AccessLogOptions configures the AccessLog middleware
+type AccessLogOptions struct {
+	// Logger writes the access log, slog.Default when nil
+	Logger *slog.Logger
+	// Fields lists the request attributes to log, DefaultFields when nil.
+	// Unknown names are ignored.
+	Fields []string
+	// SampleRate is the share of requests answered below 400 that are
+	// logged. Failed requests are always logged; 0 logs every request.
+	SampleRate float64
+}
+
+// AccessLog logs every request with log/slog once it is answered: at info
+// level, warn for client errors and error for server errors. Handlers get a
+// logger tagged with the request ID through request.GetLogger.
+func AccessLog(opts AccessLogOptions) func(http.Handler) http.Handler {
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	fields := opts.Fields
+	if fields == nil {
+		fields = DefaultFields
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			id := request.GetRequestID(r)
+			requestLogger := logger
+			if id != "" {
+				requestLogger = logger.With(FieldRequestID, id)
+			}
+			r = r.WithContext(request.WithLogger(r.Context(), requestLogger))
+
+			// Wrap the response writer to capture the status code and size
+			wrapped, rw := wrap(w)
+
+			// Requests that panic are logged as failed while the panic goes on
+			// to Recovery, keeping its stack
+			completed := false
+			defer func() {
+				status := rw.statusCode
+				if !completed && !rw.wroteHeader {
+					status = http.StatusInternalServerError
+				}
+				duration := time.Since(start)
+				if !sampled(status, opts.SampleRate) {
+					return
+				}
+
+				attrs := make([]slog.Attr, 0, len(fields))
+				for _, field := range fields {
+					switch field {
+					case FieldMethod:
+						attrs = append(attrs, slog.String(field, r.Method))
+					case FieldPath:
+						attrs = append(attrs, slog.String(field, r.URL.Path))
+					case FieldQuery:
+						attrs = append(attrs, slog.String(field, r.URL.RawQuery))
+					case FieldStatus:
+						attrs = append(attrs, slog.Int(field, status))
+					case FieldBytes:
+						attrs = append(attrs, slog.Int64(field, rw.bytes))
+					case FieldDuration:
+						attrs = append(attrs, slog.Duration(field, duration))
+					case FieldRequestID:
+						attrs = append(attrs, slog.String(field, id))
+					case FieldClientIP:
+						attrs = append(attrs, slog.String(field, request.GetClientIP(r)))
+					case FieldUserAgent:
+						attrs = append(attrs, slog.String(field, request.GetUserAgent(r)))
+					case FieldReferer:
+						attrs = append(attrs, slog.String(field, r.Referer()))
+					case FieldProto:
+						attrs = append(attrs, slog.String(field, r.Proto))
+					}
+				}
+
+				logger.LogAttrs(r.Context(), level(status), "request", attrs...)
+			}()
+
+			next.ServeHTTP(wrapped, r)
+			completed = true
+		})
+	}
+}
+
+// sampled decides whether a request answered with the status is logged
+func sampled(status int, rate float64) bool {
+	if status >= 400 || rate <= 0 || rate >= 1 {
+		return true
+	}
+	return rand.Float64() < rate
+}
+
+func level(status int) slog.Level {
+	switch {
+	case status >= 500:
+		return slog.LevelError
+	case status >= 400:
+		return slog.LevelWarn
+	}
+	return slog.LevelInfo
+}
+
+// CORSOptions configures the CORS middleware
+type CORSOptions struct {
+	// AllowedOrigins lists the origins allowed to call the server: exact
+	// origins such as "https://example.com", patterns with one wildcard
+	// such as "https://*.example.com", or "*" for any origin
+	AllowedOrigins []string
+	AllowedMethods []string
+	// AllowedHeaders lists the request headers clients may send, "*" for
+	// any header
+	AllowedHeaders []string
+	// ExposedHeaders lists the response headers scripts may read
+	ExposedHeaders []string
+	// AllowCredentials lets browsers send cookies and authorization
+	// headers. It is never granted to origins only matched by "*".
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache a preflight response, not sent
+	// when zero
+	MaxAge time.Duration
+}
+
+// CORS answers preflight requests and adds CORS headers to the responses
+// of allowed origins. Requests from other origins are served without CORS
+// headers, so browsers block their scripts from reading the response.
+func CORS(opts CORSOptions) func(http.Handler) http.Handler {
+	methods := strings.Join(opts.AllowedMethods, ", ")
+	exposed := strings.Join(opts.ExposedHeaders, ", ")
+	anyHeader := slices.Contains(opts.AllowedHeaders, "*")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := w.Header()
+			origin := r.Header.Get("Origin")
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+			// responses differ by origin, and preflights by what they ask for
+			header.Add("Vary", "Origin")
+			if preflight {
+				header.Add("Vary", "Access-Control-Request-Method")
+				header.Add("Vary", "Access-Control-Request-Headers")
+			}
+
+			if origin == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			exact, wildcard := matchOrigin(opts.AllowedOrigins, origin)
+			allowed := exact || wildcard
+
+			if !preflight {
+				if allowed {
+					allowOrigin(header, origin, exact, opts.AllowCredentials)
+					if exposed != "" {
+						header.Set("Access-Control-Expose-Headers", exposed)
+					}
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			method := r.Header.Get("Access-Control-Request-Method")
+			requested := r.Header.Values("Access-Control-Request-Headers")
+			if allowed && containsFold(opts.AllowedMethods, method) && (anyHeader || allowedHeaders(opts.AllowedHeaders, requested)) {
+				allowOrigin(header, origin, exact, opts.AllowCredentials)
+				header.Set("Access-Control-Allow-Methods", methods)
+				if len(requested) > 0 {
+					header.Set("Access-Control-Allow-Headers", strings.Join(requested, ", "))
+				}
+				if opts.MaxAge > 0 {
+					header.Set("Access-Control-Max-Age", strconv.Itoa(int(opts.MaxAge.Seconds())))
+				}
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
 
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
-			return
+// matchOrigin reports whether the origin is allowed by name or pattern, or
+// only because any origin is
+func matchOrigin(allowed []string, origin string) (exact, wildcard bool) {
+	for _, pattern := range allowed {
+		if pattern == "*" {
+			wildcard = true
+			continue
 		}
 
-		next.ServeHTTP(w, r)
-	})
+		prefix, suffix, isPattern := strings.Cut(pattern, "*")
+		if !isPattern {
+			if strings.EqualFold(pattern, origin) {
+				return true, false
+			}
+			continue
+		}
+
+		lower := strings.ToLower(origin)
+		if len(lower) > len(prefix)+len(suffix) &&
+			strings.HasPrefix(lower, strings.ToLower(prefix)) &&
+			strings.HasSuffix(lower, strings.ToLower(suffix)) {
+			return true, false
+		}
+	}
+	return false, wildcard
 }
 
-// Recovery recovers from panics
-func Recovery(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		defer func() {
-			if err := recover(); err != nil {
-				log.Printf("Panic: %+v", err)
-				log.Printf("Stack trace: %s", debug.Stack())
-				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+// allowOrigin echoes an origin matched by name or pattern, and answers "*"
+// to origins only allowed because any origin is
+func allowOrigin(header http.Header, origin string, exact, credentials bool) {
+	if !exact {
+		header.Set("Access-Control-Allow-Origin", "*")
+		return
+	}
+
+	header.Set("Access-Control-Allow-Origin", origin)
+	if credentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// allowedHeaders checks the comma separated header names of a preflight
+func allowedHeaders(allowed, requested []string) bool {
+	for _, value := range requested {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" && !containsFold(allowed, name) {
+				return false
 			}
-		}()
+		}
+	}
+	return true
+}
 
-		next.ServeHTTP(w, r)
-	})
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
 }
 
-// responseWriter wraps http.ResponseWriter to capture status code
+// Recovery recovers from panics, logging them with the request ID and
+// answering 500 unless the handler already sent the header, in which case
+// the response is left as it is. http.ErrAbortHandler is passed on so the
+// server aborts the response quietly.
+func Recovery(logger *slog.Logger) func(http.Handler) http.Handler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Wrap the response writer to know whether the header was sent
+			wrapped, rw := wrap(w)
+
+			defer func() {
+				err := recover()
+				if err == nil {
+					return
+				}
+				if err == http.ErrAbortHandler {
+					panic(err)
+				}
+
+				attrs := []any{"error", err, "stack", string(debug.Stack())}
+				if id := request.GetRequestID(r); id != "" {
+					attrs = append([]any{FieldRequestID, id}, attrs...)
+				}
+				logger.Error("panic", attrs...)
+				if !rw.wroteHeader {
+					http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				}
+			}()
+
+			next.ServeHTTP(wrapped, r)
+		})
+	}
+}
+
+// responseWriter wraps http.ResponseWriter to capture the status code, the
+// number of body bytes written and whether the header was sent
 type responseWriter struct {
 	http.ResponseWriter
-	statusCode int
+	statusCode  int
+	bytes       int64
+	wroteHeader bool
 }
 
 func (rw *responseWriter) WriteHeader(code int) {
-	rw.statusCode = code
+	// informational headers may precede the final one
+	if code >= 100 && code < 200 && code != http.StatusSwitchingProtocols {
+		rw.ResponseWriter.WriteHeader(code)
+		return
+	}
+
+	if !rw.wroteHeader {
+		rw.statusCode = code
+		rw.wroteHeader = true
+	}
 	rw.ResponseWriter.WriteHeader(code)
 }
 
 func (rw *responseWriter) Write(b []byte) (int, error) {
-	return rw.ResponseWriter.Write(b)
+	rw.wroteHeader = true
+	n, err := rw.ResponseWriter.Write(b)
+	rw.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// flusher, hijacker and pusher forward the optional interfaces of the
+// underlying writer, keeping the counts of responseWriter up to date
+type (
+	flusher  struct{ *responseWriter }
+	hijacker struct{ *responseWriter }
+	pusher   struct{ *responseWriter }
+)
+
+func (f flusher) Flush() {
+	f.wroteHeader = true
+	f.ResponseWriter.(http.Flusher).Flush()
+}
+
+func (h hijacker) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, buf, err := h.ResponseWriter.(http.Hijacker).Hijack()
+	if err == nil && !h.wroteHeader {
+		// the handler answers on the raw connection
+		h.statusCode = http.StatusSwitchingProtocols
+		h.wroteHeader = true
+	}
+	return conn, buf, err
+}
+
+func (p pusher) Push(target string, opts *http.PushOptions) error {
+	return p.ResponseWriter.(http.Pusher).Push(target, opts)
+}
+
+// wrap wraps w in a responseWriter. The returned writer implements
+// http.Flusher, http.Hijacker and http.Pusher exactly when w does, so
+// streaming, WebSockets and server push keep working behind the middleware
+// while handlers can still detect what the connection supports.
+func wrap(w http.ResponseWriter) (http.ResponseWriter, *responseWriter) {
+	rw := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+
+	_, canFlush := w.(http.Flusher)
+	_, canHijack := w.(http.Hijacker)
+	_, canPush := w.(http.Pusher)
+
+	switch {
+	case canFlush && canHijack && canPush:
+		return struct {
+			*responseWriter
+			http.Flusher
+			http.Hijacker
+			http.Pusher
+		}{rw, flusher{rw}, hijacker{rw}, pusher{rw}}, rw
+	case canFlush && canHijack:
+		return struct {
+			*responseWriter
+			http.Flusher
+			http.Hijacker
+		}{rw, flusher{rw}, hijacker{rw}}, rw
+	case canFlush && canPush:
+		return struct {
+			*responseWriter
+			http.Flusher
+			http.Pusher
+		}{rw, flusher{rw}, pusher{rw}}, rw
+	case canHijack && canPush:
+		return struct {
+			*responseWriter
+			http.Hijacker
+			http.Pusher
+		}{rw, hijacker{rw}, pusher{rw}}, rw
+	case canFlush:
+		return struct {
+			*responseWriter
+			http.Flusher
+		}{rw, flusher{rw}}, rw
+	case canHijack:
+		return struct {
+			*responseWriter
+			http.Hijacker
+		}{rw, hijacker{rw}}, rw
+	case canPush:
+		return struct {
+			*responseWriter
+			http.Pusher
+		}{rw, pusher{rw}}, rw
+	}
+	return rw, rw
 }
@@ -1,8 +1,10 @@
 package request
 
 import (
+	"context"
 	"encoding/json"
 	"io"
+	"log/slog"
 	"net/http"
 	"strconv"
 
@@ -98,3 +100,36 @@ func GetClientIP(r *http.Request) string {
 	// Fall back to remote address
 	return r.RemoteAddr
 }
+
+// contextKey keys the values the middleware stores in the request context
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	loggerKey
+)
+
+// WithRequestID returns a copy of ctx carrying the request ID
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// GetRequestID gets the request ID set by the RequestID middleware
+func GetRequestID(r *http.Request) string {
+	id, _ := r.Context().Value(requestIDKey).(string)
+	return id
+}
+
+// WithLogger returns a copy of ctx carrying the logger
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, logger)
+}
+
+// GetLogger gets the request logger set by the AccessLog middleware, which
+// tags every record with the request ID, or slog.Default when there is none
+func GetLogger(r *http.Request) *slog.Logger {
+	if logger, ok := r.Context().Value(loggerKey).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
@@ -0,0 +1,149 @@
+package server
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/patraden/code-with-kids/pkg/http/server/internal/middleware"
+	"github.com/patraden/code-with-kids/pkg/store"
+)
+
+// Middleware wraps a handler, as accepted by chi.Router.Use
+type Middleware = func(http.Handler) http.Handler
+
+// Option customizes a server created by New
+type Option func(*options)
+
+// options collects the settings of New. The configuration fills them in
+// first, so options win over it.
+type options struct {
+	defaults   bool
+	middleware []Middleware
+	logger     *slog.Logger
+	accessLog  *AccessLogConfig
+	cors       *CORSConfig
+	drawStore  store.DrawStore
+}
+
+// WithMiddleware appends middleware to the chain. It runs inside the default
+// middleware, in the order given, so it sees the request ID and its panics
+// are recovered.
+func WithMiddleware(mw ...Middleware) Option {
+	return func(o *options) {
+		o.middleware = append(o.middleware, mw...)
+	}
+}
+
+// WithoutDefaultMiddleware leaves out the request ID, recovery, access log
+// and CORS middleware, so the chain is only what WithMiddleware adds.
+// DefaultMiddleware and the constructors it uses rebuild any part of it.
+func WithoutDefaultMiddleware() Option {
+	return func(o *options) {
+		o.defaults = false
+	}
+}
+
+// WithLogger sets the logger of the access log, of recovered panics and of
+// GetLogger in handlers, in place of the AccessLog logger, format and output
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithAccessLog sets the access log configuration in place of
+// Config.AccessLog
+func WithAccessLog(config *AccessLogConfig) Option {
+	return func(o *options) {
+		o.accessLog = config
+	}
+}
+
+// WithCORS sets the cross-origin policy in place of Config.CORS
+func WithCORS(config *CORSConfig) Option {
+	return func(o *options) {
+		o.cors = config
+	}
+}
+
+// WithDrawStore sets the store of the draw API in place of Config.DrawStore
+func WithDrawStore(drawStore store.DrawStore) Option {
+	return func(o *options) {
+		o.drawStore = drawStore
+	}
+}
+
+// DefaultMiddleware returns the middleware New installs, outermost first:
+// request IDs, then recovery so panics anywhere further in are caught and
+// logged with the ID, then the access log and CORS. Nil arguments mean
+// the defaults of New.
+func DefaultMiddleware(logger *slog.Logger, accessLog *AccessLogConfig, cors *CORSConfig) []Middleware {
+	if accessLog == nil {
+		accessLog = &AccessLogConfig{}
+	}
+	if logger == nil {
+		logger = accessLog.logger()
+	}
+
+	return []Middleware{
+		RequestIDMiddleware,
+		RecoveryMiddleware(logger),
+		AccessLogMiddleware(logger, accessLog),
+		CORSMiddleware(cors),
+	}
+}
+
+// RequestIDMiddleware takes the request ID from the X-Request-ID header or
+// generates one, and sends it back in the response
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return middleware.RequestID(next)
+}
+
+// RecoveryMiddleware recovers from panics, logs them with the request ID and
+// answers 500. Nil logs with slog.Default.
+func RecoveryMiddleware(logger *slog.Logger) Middleware {
+	return middleware.Recovery(logger)
+}
+
+// AccessLogMiddleware logs every request. A nil logger is created from the
+// configuration, and a nil configuration logs text to stderr.
+func AccessLogMiddleware(logger *slog.Logger, config *AccessLogConfig) Middleware {
+	if config == nil {
+		config = &AccessLogConfig{}
+	}
+	if logger == nil {
+		logger = config.logger()
+	}
+
+	return middleware.AccessLog(middleware.AccessLogOptions{
+		Logger:     logger,
+		Fields:     config.Fields,
+		SampleRate: config.SampleRate,
+	})
+}
+
+// CORSMiddleware applies the cross-origin policy, DefaultCORSConfig when nil.
+// Empty allowed methods and headers are taken from DefaultCORSConfig.
+func CORSMiddleware(config *CORSConfig) Middleware {
+	defaults := DefaultCORSConfig()
+	if config == nil {
+		config = defaults
+	}
+
+	methods, headers := config.AllowedMethods, config.AllowedHeaders
+	if len(methods) == 0 {
+		methods = defaults.AllowedMethods
+	}
+	if len(headers) == 0 {
+		headers = defaults.AllowedHeaders
+	}
+
+	return middleware.CORS(middleware.CORSOptions{
+		AllowedOrigins:   config.AllowedOrigins,
+		AllowedMethods:   methods,
+		AllowedHeaders:   headers,
+		ExposedHeaders:   config.ExposedHeaders,
+		AllowCredentials: config.AllowCredentials,
+		MaxAge:           config.MaxAge,
+	})
+}
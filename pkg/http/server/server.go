@@ -3,6 +3,8 @@ package server
 import (
 	"context"
 	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
@@ -10,17 +12,20 @@ import (
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/patraden/code-with-kids/pkg/http/server/internal/draws"
 	"github.com/patraden/code-with-kids/pkg/http/server/internal/health"
-	"github.com/patraden/code-with-kids/pkg/http/server/internal/middleware"
+	"github.com/patraden/code-with-kids/pkg/http/server/internal/live"
 	"github.com/patraden/code-with-kids/pkg/http/server/internal/request"
 	"github.com/patraden/code-with-kids/pkg/http/server/internal/response"
+	"github.com/patraden/code-with-kids/pkg/store"
 )
 
 // Server represents an HTTP server with common functionality
 type Server struct {
-	router *chi.Mux
-	server *http.Server
-	config *Config
+	router    *chi.Mux
+	server    *http.Server
+	config    *Config
+	drawStore store.DrawStore
 }
 
 // Config holds server configuration
@@ -30,6 +35,88 @@ type Config struct {
 	WriteTimeout time.Duration
 	IdleTimeout  time.Duration
 	Host         string
+	// DrawStore keeps the draws of the draw API, in memory when nil
+	DrawStore store.DrawStore
+	// AccessLog configures request logging, text to stderr when nil
+	AccessLog *AccessLogConfig
+	// CORS is the cross-origin policy, DefaultCORSConfig when nil
+	CORS *CORSConfig
+	// LiveRetention is how long finished live sessions stay available, ten
+	// minutes when zero
+	LiveRetention time.Duration
+}
+
+// CORSConfig is the policy for requests from scripts on other origins
+type CORSConfig struct {
+	// AllowedOrigins lists the origins allowed to call the server: exact
+	// origins such as "https://example.com", patterns with one wildcard
+	// such as "https://*.example.com", or "*" for any origin
+	AllowedOrigins []string
+	// AllowedMethods lists the methods allowed by preflight requests, those
+	// of DefaultCORSConfig when empty
+	AllowedMethods []string
+	// AllowedHeaders lists the request headers clients may send, "*" for
+	// any header, those of DefaultCORSConfig when empty
+	AllowedHeaders []string
+	// ExposedHeaders lists the response headers scripts may read
+	ExposedHeaders []string
+	// AllowCredentials lets browsers send cookies and authorization
+	// headers. Origins only matched by "*" never get credentials, so list
+	// the trusted origins.
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache a preflight response, not sent
+	// when zero
+	MaxAge time.Duration
+}
+
+// DefaultCORSConfig allows any origin to call the server without
+// credentials
+func DefaultCORSConfig() *CORSConfig {
+	return &CORSConfig{
+		AllowedOrigins: []string{"*"},
+		AllowedMethods: []string{
+			http.MethodGet, http.MethodPost, http.MethodPut,
+			http.MethodPatch, http.MethodDelete, http.MethodOptions,
+		},
+		AllowedHeaders: []string{"Content-Type", "Authorization", "X-Request-ID", "Last-Event-ID"},
+		ExposedHeaders: []string{"Location", "X-Request-ID"},
+	}
+}
+
+// AccessLogConfig configures the access log written for every request
+type AccessLogConfig struct {
+	// Logger writes the log. When nil a logger is created from Format and
+	// Output.
+	Logger *slog.Logger
+	// Format is "text" or "json", text when empty
+	Format string
+	// Output receives the log, os.Stderr when nil
+	Output io.Writer
+	// Fields lists the request attributes to log, out of method, path,
+	// query, status, bytes, duration, request_id, client_ip, user_agent,
+	// referer and proto. When nil every field but query, referer and proto
+	// is logged.
+	Fields []string
+	// SampleRate is the share of requests answered below 400 that are
+	// logged, between 0 and 1. Failed requests are always logged; 0 logs
+	// every request.
+	SampleRate float64
+}
+
+// logger returns the configured logger or creates one
+func (c *AccessLogConfig) logger() *slog.Logger {
+	if c.Logger != nil {
+		return c.Logger
+	}
+
+	output := c.Output
+	if output == nil {
+		output = os.Stderr
+	}
+	if c.Format == "json" {
+		return slog.New(slog.NewJSONHandler(output, nil))
+	}
+	return slog.New(slog.NewTextHandler(output, nil))
 }
 
 // DefaultConfig returns a default server configuration
@@ -43,18 +130,29 @@ func DefaultConfig() *Config {
 	}
 }
 
-// New creates a new HTTP server with the given configuration
-func New(config *Config) *Server {
+// New creates a new HTTP server with the given configuration. Options
+// override the configuration and control the middleware chain; without
+// them every request goes through DefaultMiddleware.
+func New(config *Config, opts ...Option) *Server {
 	if config == nil {
 		config = DefaultConfig()
 	}
 
-	router := chi.NewRouter()
+	o := &options{
+		defaults:  true,
+		accessLog: config.AccessLog,
+		cors:      config.CORS,
+		drawStore: config.DrawStore,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
 
-	// Add common middleware
-	router.Use(middleware.Logging)
-	router.Use(middleware.CORS)
-	router.Use(middleware.Recovery)
+	router := chi.NewRouter()
+	if o.defaults {
+		router.Use(DefaultMiddleware(o.logger, o.accessLog, o.cors)...)
+	}
+	router.Use(o.middleware...)
 
 	server := &http.Server{
 		Addr:         fmt.Sprintf("%s:%d", config.Host, config.Port),
@@ -64,10 +162,16 @@ func New(config *Config) *Server {
 		IdleTimeout:  config.IdleTimeout,
 	}
 
+	drawStore := o.drawStore
+	if drawStore == nil {
+		drawStore = store.NewMemoryStore()
+	}
+
 	return &Server{
-		router: router,
-		server: server,
-		config: config,
+		router:    router,
+		server:    server,
+		config:    config,
+		drawStore: drawStore,
 	}
 }
 
@@ -169,6 +273,48 @@ func (s *Server) AddHealthRoutes() {
 	s.AddGET("/info", health.InfoHandler)
 }
 
+// AddDrawRoutes adds the draw API, keeping draws in the configured
+// DrawStore, shared with AddLiveRoutes:
+//
+//	POST   /api/draws                  run a draw of the posted teams
+//	GET    /api/draws                  list the stored draws
+//	GET    /api/draws/{id}             the draw with its pots and fixtures
+//	DELETE /api/draws/{id}             delete the draw
+//	GET    /api/draws/{id}/history     changes made to the draw
+//	GET    /api/draws/{id}/fixtures    fixtures, filtered by ?team= and ?matchday=
+//	GET    /api/draws/{id}/standings   league phase table from the results
+//	POST   /api/draws/{id}/results     report match results
+func (s *Server) AddDrawRoutes() {
+	h := draws.NewHandler(s.drawStore)
+	s.AddPOST("/api/draws", h.Create)
+	s.AddGET("/api/draws", h.List)
+	s.AddGET("/api/draws/{id}", h.Get)
+	s.AddDELETE("/api/draws/{id}", h.Delete)
+	s.AddGET("/api/draws/{id}/history", h.History)
+	s.AddGET("/api/draws/{id}/fixtures", h.Fixtures)
+	s.AddGET("/api/draws/{id}/standings", h.Standings)
+	s.AddPOST("/api/draws/{id}/results", h.AddResults)
+}
+
+// AddLiveRoutes adds live draw sessions, which present a stored draw step
+// by step to every connected viewer as server-sent events:
+//
+//	POST   /api/live                 start presenting a stored draw
+//	GET    /api/live/{id}            state of the session
+//	GET    /api/live/{id}/events     event stream, replaying missed steps
+//	POST   /api/live/{id}/pause      hold the ceremony
+//	POST   /api/live/{id}/resume     continue the ceremony
+//	DELETE /api/live/{id}            stop the session
+func (s *Server) AddLiveRoutes() {
+	h := live.NewHub(s.drawStore, s.config.LiveRetention)
+	s.AddPOST("/api/live", h.Create)
+	s.AddGET("/api/live/{id}", h.Get)
+	s.AddGET("/api/live/{id}/events", h.Events)
+	s.AddPOST("/api/live/{id}/pause", h.Pause)
+	s.AddPOST("/api/live/{id}/resume", h.Resume)
+	s.AddDELETE("/api/live/{id}", h.Delete)
+}
+
 // Response helpers - re-export from internal package for convenience
 func SuccessResponse(w http.ResponseWriter, data interface{}, message string) {
 	response.Success(w, data, message)
@@ -254,3 +400,14 @@ func GetUserAgent(r *http.Request) string {
 func GetClientIP(r *http.Request) string {
 	return request.GetClientIP(r)
 }
+
+// GetRequestID gets the ID of the request, also sent back in the
+// X-Request-ID response header
+func GetRequestID(r *http.Request) string {
+	return request.GetRequestID(r)
+}
+
+// GetLogger gets a logger that tags every record with the request ID
+func GetLogger(r *http.Request) *slog.Logger {
+	return request.GetLogger(r)
+}
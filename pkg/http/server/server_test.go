@@ -1,10 +1,21 @@
 package server
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/patraden/code-with-kids/pkg/draw"
+	"github.com/patraden/code-with-kids/pkg/store"
 )
 
 func TestNewServer(t *testing.T) {
@@ -129,3 +140,805 @@ func TestHealthRoutes(t *testing.T) {
 		t.Errorf("Expected status 200, got %d", w2.Code)
 	}
 }
+
+func TestDrawRoutes(t *testing.T) {
+	drawStore, err := store.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	config := DefaultConfig()
+	config.DrawStore = drawStore
+	srv := New(config)
+	srv.AddDrawRoutes()
+
+	teams, err := draw.ReadTeamsFile("../../../example/teams.txt")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	body, _ := json.Marshal(map[string]interface{}{"teams": teams, "seed": 7})
+
+	serve := func(method, path, body string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		w := httptest.NewRecorder()
+		srv.Router().ServeHTTP(w, req)
+
+		var resp map[string]interface{}
+		if w.Body.Len() == 0 {
+			return w, resp
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("%s %s: invalid JSON response %q", method, path, w.Body.String())
+		}
+		return w, resp
+	}
+
+	w, resp := serve("POST", "/api/draws", string(body))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+	created := resp["data"].(map[string]interface{})
+	id := created["id"].(string)
+	if w.Header().Get("Location") != "/api/draws/"+id {
+		t.Errorf("Expected Location of the new draw, got %q", w.Header().Get("Location"))
+	}
+	if created["seed"].(float64) != 7 || len(created["fixtures"].([]interface{})) != 144 {
+		t.Errorf("Expected seed 7 and 144 fixtures, got %v and %d", created["seed"], len(created["fixtures"].([]interface{})))
+	}
+
+	w, _ = serve("GET", "/api/draws/"+id, "")
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+
+	w, resp = serve("GET", "/api/draws/"+id+"/fixtures?team=Barcelona", "")
+	fixtures := resp["data"].([]interface{})
+	if w.Code != http.StatusOK || len(fixtures) != 8 {
+		t.Fatalf("Expected 8 fixtures of Barcelona, got status %d and %d fixtures", w.Code, len(fixtures))
+	}
+
+	f := fixtures[0].(map[string]interface{})
+	result := fmt.Sprintf(`[{"home": %q, "away": %q, "home_goals": 2, "away_goals": 1}]`, f["home"], f["away"])
+	w, _ = serve("POST", "/api/draws/"+id+"/results", result)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	w, _ = serve("POST", "/api/draws/"+id+"/results", result)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a duplicate result, got %d", w.Code)
+	}
+
+	w, resp = serve("GET", "/api/draws/"+id+"/standings", "")
+	rows := resp["data"].(map[string]interface{})["rows"].([]interface{})
+	top := rows[0].(map[string]interface{})
+	if w.Code != http.StatusOK || top["team"] != f["home"] || top["points"].(float64) != 3 {
+		t.Errorf("Expected %v on top with 3 points, got %v", f["home"], top)
+	}
+
+	w, resp = serve("GET", "/api/draws", "")
+	if list := resp["data"].([]interface{}); w.Code != http.StatusOK || len(list) != 1 {
+		t.Errorf("Expected 1 stored draw, got status %d and %d draws", w.Code, len(list))
+	}
+
+	w, _ = serve("DELETE", "/api/draws/"+id, "")
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", w.Code)
+	}
+	for _, path := range []string{"/api/draws/" + id, "/api/draws/unknown"} {
+		w, _ = serve("GET", path, "")
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404 for %s, got %d", path, w.Code)
+		}
+	}
+
+	w, resp = serve("GET", "/api/draws/"+id+"/history", "")
+	if history := resp["data"].([]interface{}); w.Code != http.StatusOK || len(history) != 3 {
+		t.Errorf("Expected created, updated and deleted in the history, got status %d and %v", w.Code, history)
+	}
+
+	w, _ = serve("POST", "/api/draws", `{"teams": [{"name": "Porto"}]}`)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for too few teams, got %d", w.Code)
+	}
+
+	w, _ = serve("POST", "/api/draws", `{"teams": [], "profile": "nba"}`)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an unknown profile, got %d", w.Code)
+	}
+}
+
+func TestLiveRoutes(t *testing.T) {
+	srv := New(nil)
+	srv.AddDrawRoutes()
+	srv.AddLiveRoutes()
+	ts := httptest.NewServer(srv.Router())
+	defer ts.Close()
+
+	post := func(path, body string) map[string]interface{} {
+		resp, err := http.Post(ts.URL+path, "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("POST %s: expected status 201, got %d", path, resp.StatusCode)
+		}
+
+		var decoded map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&decoded)
+		return decoded["data"].(map[string]interface{})
+	}
+
+	// stream reads the event stream and returns the ID and kind of every event
+	stream := func(path, lastEventID string) ([]string, []string) {
+		req, _ := http.NewRequest("GET", ts.URL+path, nil)
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		defer resp.Body.Close()
+		if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+			t.Fatalf("Expected an event stream, got %q", ct)
+		}
+
+		var ids, kinds []string
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if id, ok := strings.CutPrefix(scanner.Text(), "id: "); ok {
+				ids = append(ids, id)
+			}
+			if kind, ok := strings.CutPrefix(scanner.Text(), "event: "); ok {
+				kinds = append(kinds, kind)
+			}
+		}
+		return ids, kinds
+	}
+
+	teams, err := draw.ReadTeamsFile("../../../example/teams.txt")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	body, _ := json.Marshal(map[string]interface{}{"teams": teams, "seed": 7})
+	drawID := post("/api/draws", string(body))["id"].(string)
+
+	session := post("/api/live", fmt.Sprintf(`{"draw_id": %q, "delay_ms": 0}`, drawID))
+	events := fmt.Sprintf("/api/live/%s/events", session["id"])
+
+	ids, kinds := stream(events, "")
+	if len(kinds) == 0 || kinds[0] != string(draw.EventPot) || kinds[len(kinds)-1] != string(draw.EventFinished) {
+		t.Fatalf("Expected the whole ceremony from the pots to the end, got %v", kinds)
+	}
+	if ids[0] != "1" || ids[len(ids)-1] != fmt.Sprint(len(ids)) {
+		t.Errorf("Expected event IDs 1 to %d, got %s to %s", len(ids), ids[0], ids[len(ids)-1])
+	}
+
+	// a viewer joining after the end still gets the history it missed
+	late, _ := stream(events, "10")
+	if len(late) != len(ids)-10 || late[0] != "11" {
+		t.Errorf("Expected the %d events after 10, got %d starting at %v", len(ids)-10, len(late), late)
+	}
+
+	resp, err := http.Get(ts.URL + "/api/live/unknown/events")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown session, got %d", resp.StatusCode)
+	}
+
+	resp, err = http.Post(ts.URL+"/api/live", "application/json", strings.NewReader(`{"draw_id": "0000000000000000"}`))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown draw, got %d", resp.StatusCode)
+	}
+}
+
+func TestLiveSessionsExpire(t *testing.T) {
+	config := DefaultConfig()
+	config.LiveRetention = 10 * time.Millisecond
+	srv := New(config, WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	srv.AddDrawRoutes()
+	srv.AddLiveRoutes()
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		srv.Router().ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return w
+	}
+
+	teams, err := draw.ReadTeamsFile("../../../example/teams.txt")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	body, _ := json.Marshal(map[string]interface{}{"teams": teams, "seed": 7})
+	var created struct {
+		Data struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	json.Unmarshal(serve("POST", "/api/draws", string(body)).Body.Bytes(), &created)
+
+	w := serve("POST", "/api/live", fmt.Sprintf(`{"draw_id": %q, "delay_ms": 0}`, created.Data.ID))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+	session := w.Header().Get("Location")
+
+	// the session finishes at once and is evicted shortly after
+	deadline := time.Now().Add(5 * time.Second)
+	for serve("GET", session, "").Code != http.StatusNotFound {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %s to be evicted after finishing", session)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	padding := strings.Repeat(" ", 2<<10)
+	if w := serve("POST", "/api/live", `{"draw_id": "`+created.Data.ID+`"`+padding+`}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an oversized body, got %d", w.Code)
+	}
+}
+
+func TestStreamingThroughMiddleware(t *testing.T) {
+	srv := New(nil)
+
+	next := make(chan struct{})
+	srv.AddGET("/stream", func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			t.Error("Expected the middleware to keep http.Flusher")
+			return
+		}
+		for i := range 3 {
+			fmt.Fprintf(w, "chunk %d\n", i)
+			flusher.Flush()
+			<-next
+		}
+	})
+
+	ts := httptest.NewServer(srv.Router())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/stream")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer resp.Body.Close()
+
+	// every chunk arrives while the handler is still waiting to write the next
+	reader := bufio.NewReader(resp.Body)
+	for i := range 3 {
+		line, err := reader.ReadString('\n')
+		if err != nil || line != fmt.Sprintf("chunk %d\n", i) {
+			t.Fatalf("Expected chunk %d before the handler returned, got %q, %v", i, line, err)
+		}
+		next <- struct{}{}
+	}
+}
+
+func TestHijackThroughMiddleware(t *testing.T) {
+	srv := New(nil)
+	srv.AddGET("/upgrade", func(w http.ResponseWriter, r *http.Request) {
+		hijacker, ok := w.(http.Hijacker)
+		if !ok {
+			t.Error("Expected the middleware to keep http.Hijacker")
+			return
+		}
+		conn, buf, err := hijacker.Hijack()
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+			return
+		}
+		defer conn.Close()
+		buf.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 8\r\nConnection: close\r\n\r\nhijacked")
+		buf.Flush()
+	})
+
+	ts := httptest.NewServer(srv.Router())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/upgrade")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "hijacked" {
+		t.Errorf("Expected the hijacked response, got %q", body)
+	}
+}
+
+// pushRecorder is a response recorder of an HTTP/2 connection
+type pushRecorder struct {
+	*httptest.ResponseRecorder
+	pushed []string
+}
+
+func (p *pushRecorder) Push(target string, opts *http.PushOptions) error {
+	p.pushed = append(p.pushed, target)
+	return nil
+}
+
+func TestOptionalInterfacesThroughMiddleware(t *testing.T) {
+	var logs bytes.Buffer
+	config := DefaultConfig()
+	config.AccessLog = &AccessLogConfig{Output: &logs}
+	srv := New(config)
+	srv.AddGET("/page", func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := w.(http.Hijacker); ok {
+			t.Error("Expected no http.Hijacker when the connection cannot be hijacked")
+		}
+		pusher, ok := w.(http.Pusher)
+		if !ok {
+			t.Error("Expected the middleware to keep http.Pusher")
+			return
+		}
+		pusher.Push("/static/app.js", nil)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("hello"))
+	})
+
+	w := &pushRecorder{ResponseRecorder: httptest.NewRecorder()}
+	srv.Router().ServeHTTP(w, httptest.NewRequest("GET", "/page", nil))
+
+	if len(w.pushed) != 1 || w.pushed[0] != "/static/app.js" {
+		t.Errorf("Expected /static/app.js to be pushed, got %v", w.pushed)
+	}
+	if !strings.Contains(logs.String(), "status=201 bytes=5 ") {
+		t.Errorf("Expected the status and size in the access log, got %q", logs.String())
+	}
+}
+
+func TestAccessLog(t *testing.T) {
+	var logs bytes.Buffer
+	config := DefaultConfig()
+	config.AccessLog = &AccessLogConfig{
+		Format:     "json",
+		Output:     &logs,
+		Fields:     []string{"method", "path", "status", "request_id"},
+		SampleRate: 0.000001,
+	}
+	srv := New(config)
+	srv.AddGET("/hello", func(w http.ResponseWriter, r *http.Request) {
+		GetLogger(r).Info("greeting", "id", GetRequestID(r))
+		w.Write([]byte("hello"))
+	})
+	srv.AddGET("/missing", func(w http.ResponseWriter, r *http.Request) {
+		NotFound(w, "missing")
+	})
+
+	serve := func(path, id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		if id != "" {
+			req.Header.Set("X-Request-ID", id)
+		}
+		w := httptest.NewRecorder()
+		srv.Router().ServeHTTP(w, req)
+		return w
+	}
+
+	records := func() []map[string]interface{} {
+		var decoded []map[string]interface{}
+		for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+			if line == "" {
+				continue
+			}
+			var record map[string]interface{}
+			if err := json.Unmarshal([]byte(line), &record); err != nil {
+				t.Fatalf("Expected JSON log lines, got %q", line)
+			}
+			decoded = append(decoded, record)
+		}
+		logs.Reset()
+		return decoded
+	}
+
+	// a sampled out request still reaches the handler logger
+	w := serve("/hello", "abc-123")
+	if got := w.Header().Get("X-Request-ID"); got != "abc-123" {
+		t.Errorf("Expected the request ID to be propagated, got %q", got)
+	}
+	logged := records()
+	if len(logged) != 1 || logged[0]["msg"] != "greeting" || logged[0]["request_id"] != "abc-123" || logged[0]["id"] != "abc-123" {
+		t.Errorf("Expected only the handler record tagged with the request ID, got %v", logged)
+	}
+
+	w = serve("/missing", "bad id\n")
+	id := w.Header().Get("X-Request-ID")
+	if id == "" || id == "bad id\n" {
+		t.Errorf("Expected a generated request ID in place of an invalid one, got %q", id)
+	}
+	logged = records()
+	want := map[string]interface{}{"level": "WARN", "msg": "request", "method": "GET", "path": "/missing", "status": float64(404), "request_id": id}
+	if len(logged) != 1 {
+		t.Fatalf("Expected the failed request to be logged, got %v", logged)
+	}
+	for key, value := range want {
+		if logged[0][key] != value {
+			t.Errorf("Expected %s %v, got %v", key, value, logged[0][key])
+		}
+	}
+	if _, ok := logged[0]["user_agent"]; ok {
+		t.Error("Expected only the configured fields")
+	}
+}
+
+func TestRecoveryLogsPanics(t *testing.T) {
+	var logs bytes.Buffer
+	config := DefaultConfig()
+	config.AccessLog = &AccessLogConfig{Logger: slog.New(slog.NewTextHandler(&logs, nil))}
+	srv := New(config)
+	srv.AddGET("/panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+
+	w := httptest.NewRecorder()
+	srv.Router().ServeHTTP(w, httptest.NewRequest("GET", "/panic", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", w.Code)
+	}
+	id := w.Header().Get("X-Request-ID")
+	if !strings.Contains(logs.String(), "error=boom") || !strings.Contains(logs.String(), "request_id="+id) {
+		t.Errorf("Expected the panic logged with the request ID, got %q", logs.String())
+	}
+	if !strings.Contains(logs.String(), "level=ERROR msg=request") {
+		t.Errorf("Expected the failed request in the access log, got %q", logs.String())
+	}
+
+	// a handler that already answered keeps its response
+	srv.AddGET("/partial", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("partial"))
+		panic("late boom")
+	})
+	w = httptest.NewRecorder()
+	srv.Router().ServeHTTP(w, httptest.NewRequest("GET", "/partial", nil))
+
+	if w.Code != http.StatusOK || w.Body.String() != "partial" {
+		t.Errorf("Expected the partial response alone, got %d %q", w.Code, w.Body.String())
+	}
+	if !strings.Contains(logs.String(), `error="late boom"`) {
+		t.Errorf("Expected the late panic logged, got %q", logs.String())
+	}
+}
+
+func TestCORS(t *testing.T) {
+	config := DefaultConfig()
+	config.CORS = &CORSConfig{
+		AllowedOrigins:   []string{"https://kids.example.com", "https://*.school.example", "*"},
+		AllowedMethods:   []string{"GET", "POST", "PATCH"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization"},
+		ExposedHeaders:   []string{"X-Request-ID"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	}
+	srv := New(config)
+	srv.AddPATCH("/api/draws/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("patched"))
+	})
+
+	tests := []struct {
+		name        string
+		method      string
+		headers     map[string]string
+		wantStatus  int
+		wantHeaders map[string]string
+	}{
+		{
+			name:       "preflight from a listed origin",
+			method:     "OPTIONS",
+			headers:    map[string]string{"Origin": "https://kids.example.com", "Access-Control-Request-Method": "PATCH", "Access-Control-Request-Headers": "content-type, authorization"},
+			wantStatus: http.StatusNoContent,
+			wantHeaders: map[string]string{
+				"Access-Control-Allow-Origin":      "https://kids.example.com",
+				"Access-Control-Allow-Credentials": "true",
+				"Access-Control-Allow-Methods":     "GET, POST, PATCH",
+				"Access-Control-Allow-Headers":     "content-type, authorization",
+				"Access-Control-Max-Age":           "600",
+			},
+		},
+		{
+			name:       "preflight from an origin matching a pattern",
+			method:     "OPTIONS",
+			headers:    map[string]string{"Origin": "https://team1.school.example", "Access-Control-Request-Method": "POST"},
+			wantStatus: http.StatusNoContent,
+			wantHeaders: map[string]string{
+				"Access-Control-Allow-Origin":      "https://team1.school.example",
+				"Access-Control-Allow-Credentials": "true",
+			},
+		},
+		{
+			name:       "preflight for a method that is not allowed",
+			method:     "OPTIONS",
+			headers:    map[string]string{"Origin": "https://kids.example.com", "Access-Control-Request-Method": "DELETE"},
+			wantStatus: http.StatusNoContent,
+			wantHeaders: map[string]string{
+				"Access-Control-Allow-Origin":  "",
+				"Access-Control-Allow-Methods": "",
+			},
+		},
+		{
+			name:       "preflight with a header that is not allowed",
+			method:     "OPTIONS",
+			headers:    map[string]string{"Origin": "https://kids.example.com", "Access-Control-Request-Method": "GET", "Access-Control-Request-Headers": "X-Secret"},
+			wantStatus: http.StatusNoContent,
+			wantHeaders: map[string]string{
+				"Access-Control-Allow-Origin": "",
+			},
+		},
+		{
+			name:       "request from any other origin gets no credentials",
+			method:     "PATCH",
+			headers:    map[string]string{"Origin": "https://elsewhere.example"},
+			wantStatus: http.StatusOK,
+			wantHeaders: map[string]string{
+				"Access-Control-Allow-Origin":      "*",
+				"Access-Control-Allow-Credentials": "",
+				"Access-Control-Expose-Headers":    "X-Request-ID",
+			},
+		},
+		{
+			name:       "pattern does not match the bare domain",
+			method:     "PATCH",
+			headers:    map[string]string{"Origin": "https://.school.example"},
+			wantStatus: http.StatusOK,
+			wantHeaders: map[string]string{
+				"Access-Control-Allow-Origin": "*",
+			},
+		},
+		{
+			name:       "same origin request",
+			method:     "PATCH",
+			wantStatus: http.StatusOK,
+			wantHeaders: map[string]string{
+				"Access-Control-Allow-Origin": "",
+				"Vary":                        "Origin",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/draws/1", nil)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			w := httptest.NewRecorder()
+			srv.Router().ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+			for key, want := range tt.wantHeaders {
+				if got := w.Header().Get(key); got != want {
+					t.Errorf("Expected %s %q, got %q", key, want, got)
+				}
+			}
+		})
+	}
+
+	// preflight responses depend on what the browser asks for
+	req := httptest.NewRequest("OPTIONS", "/api/draws/1", nil)
+	req.Header.Set("Origin", "https://kids.example.com")
+	req.Header.Set("Access-Control-Request-Method", "GET")
+	w := httptest.NewRecorder()
+	srv.Router().ServeHTTP(w, req)
+	if vary := strings.Join(w.Header().Values("Vary"), ", "); vary != "Origin, Access-Control-Request-Method, Access-Control-Request-Headers" {
+		t.Errorf("Expected preflights to vary by origin, method and headers, got %q", vary)
+	}
+}
+
+func TestDefaultCORS(t *testing.T) {
+	srv := New(nil)
+	srv.AddDrawRoutes()
+
+	req := httptest.NewRequest("OPTIONS", "/api/draws/1", nil)
+	req.Header.Set("Origin", "https://example.com")
+	req.Header.Set("Access-Control-Request-Method", "PATCH")
+	w := httptest.NewRecorder()
+	srv.Router().ServeHTTP(w, req)
+
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("Expected any origin to be allowed, got %q", got)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Methods"); !strings.Contains(got, "PATCH") {
+		t.Errorf("Expected PATCH to be allowed, got %q", got)
+	}
+}
+
+func TestCORSOnlyOrigins(t *testing.T) {
+	// the configuration of the README example
+	srv := New(nil, WithCORS(&CORSConfig{AllowedOrigins: []string{"https://kids.example.com"}}))
+	srv.AddDrawRoutes()
+
+	req := httptest.NewRequest("OPTIONS", "/api/draws", nil)
+	req.Header.Set("Origin", "https://kids.example.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	req.Header.Set("Access-Control-Request-Headers", "Content-Type")
+	w := httptest.NewRecorder()
+	srv.Router().ServeHTTP(w, req)
+
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "https://kids.example.com" {
+		t.Errorf("Expected the origin to be allowed, got %q", got)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Methods"); !strings.Contains(got, "POST") {
+		t.Errorf("Expected the default methods, got %q", got)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Headers"); !strings.Contains(got, "Content-Type") {
+		t.Errorf("Expected the default headers, got %q", got)
+	}
+}
+
+func TestOptions(t *testing.T) {
+	var order []string
+	trace := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, name+":"+GetRequestID(r))
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	t.Run("middleware runs inside the defaults", func(t *testing.T) {
+		order = nil
+		srv := New(nil, WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))), WithMiddleware(trace("first"), trace("second")))
+		srv.AddGET("/", func(w http.ResponseWriter, r *http.Request) {})
+
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-Request-ID", "abc")
+		srv.Router().ServeHTTP(httptest.NewRecorder(), req)
+
+		if strings.Join(order, ",") != "first:abc,second:abc" {
+			t.Errorf("Expected both middleware in order with the request ID, got %v", order)
+		}
+	})
+
+	t.Run("without default middleware", func(t *testing.T) {
+		order = nil
+		srv := New(nil, WithoutDefaultMiddleware(), WithMiddleware(trace("only")))
+		srv.AddGET("/", func(w http.ResponseWriter, r *http.Request) {})
+
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Origin", "https://kids.example.com")
+		w := httptest.NewRecorder()
+		srv.Router().ServeHTTP(w, req)
+
+		if strings.Join(order, ",") != "only:" {
+			t.Errorf("Expected only the given middleware without a request ID, got %v", order)
+		}
+		if w.Header().Get("X-Request-ID") != "" || w.Header().Get("Access-Control-Allow-Origin") != "" {
+			t.Errorf("Expected no default middleware headers, got %v", w.Header())
+		}
+	})
+
+	t.Run("custom chain", func(t *testing.T) {
+		var logs bytes.Buffer
+		logger := slog.New(slog.NewTextHandler(&logs, nil))
+		srv := New(nil, WithoutDefaultMiddleware(), WithMiddleware(
+			RecoveryMiddleware(logger),
+			RequestIDMiddleware,
+			CORSMiddleware(&CORSConfig{AllowedOrigins: []string{"https://kids.example.com"}}),
+		))
+		srv.AddGET("/panic", func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		})
+
+		req := httptest.NewRequest("GET", "/panic", nil)
+		req.Header.Set("Origin", "https://kids.example.com")
+		w := httptest.NewRecorder()
+		srv.Router().ServeHTTP(w, req)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("Expected status 500, got %d", w.Code)
+		}
+		if w.Header().Get("Access-Control-Allow-Origin") != "https://kids.example.com" {
+			t.Errorf("Expected CORS headers, got %v", w.Header())
+		}
+		if !strings.Contains(logs.String(), "msg=panic") || strings.Contains(logs.String(), "request_id=") {
+			t.Errorf("Expected the panic logged without a request ID, got %q", logs.String())
+		}
+		if strings.Contains(logs.String(), "msg=request") {
+			t.Errorf("Expected no access log, got %q", logs.String())
+		}
+	})
+
+	t.Run("options override the configuration", func(t *testing.T) {
+		var configured, logs bytes.Buffer
+		config := DefaultConfig()
+		config.AccessLog = &AccessLogConfig{Output: &configured}
+		config.CORS = &CORSConfig{AllowedOrigins: []string{"https://other.example"}}
+		config.DrawStore = store.NewMemoryStore()
+		drawStore := store.NewMemoryStore()
+
+		srv := New(config,
+			WithLogger(slog.New(slog.NewJSONHandler(&logs, nil))),
+			WithCORS(&CORSConfig{AllowedOrigins: []string{"https://kids.example.com"}}),
+			WithDrawStore(drawStore),
+		)
+		srv.AddGET("/api/hello", func(w http.ResponseWriter, r *http.Request) {
+			GetLogger(r).Info("saying hello")
+		})
+		srv.AddDrawRoutes()
+
+		req := httptest.NewRequest("GET", "/api/hello", nil)
+		req.Header.Set("Origin", "https://kids.example.com")
+		w := httptest.NewRecorder()
+		srv.Router().ServeHTTP(w, req)
+
+		if w.Header().Get("Access-Control-Allow-Origin") != "https://kids.example.com" {
+			t.Errorf("Expected the CORS option to win, got %v", w.Header())
+		}
+		if configured.Len() != 0 {
+			t.Errorf("Expected nothing in the configured output, got %q", configured.String())
+		}
+		if !strings.Contains(logs.String(), `"msg":"saying hello"`) || !strings.Contains(logs.String(), `"msg":"request"`) {
+			t.Errorf("Expected handler and access logs in the logger, got %q", logs.String())
+		}
+
+		teams, err := draw.ReadTeamsFile("../../../example/teams.txt")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		body, _ := json.Marshal(map[string]interface{}{"teams": teams, "seed": 1})
+		w = httptest.NewRecorder()
+		srv.Router().ServeHTTP(w, httptest.NewRequest("POST", "/api/draws", bytes.NewReader(body)))
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+		}
+		if records, _ := drawStore.List(); len(records) != 1 {
+			t.Errorf("Expected the draw in the option store, got %d records", len(records))
+		}
+	})
+
+	t.Run("access log options", func(t *testing.T) {
+		var logs bytes.Buffer
+		srv := New(nil, WithAccessLog(&AccessLogConfig{Output: &logs, Format: "json", Fields: []string{"status"}}))
+		srv.AddGET("/", func(w http.ResponseWriter, r *http.Request) {})
+
+		srv.Router().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+		if !strings.Contains(logs.String(), `"msg":"request","status":200}`) {
+			t.Errorf("Expected the configured access log, got %q", logs.String())
+		}
+	})
+}
+
+// failingHandler is a slog handler that panics on access log records
+type failingHandler struct {
+	slog.Handler
+}
+
+func (h failingHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Message == "request" {
+		panic("log failure")
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h failingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return failingHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func TestRecoveryCatchesAccessLogPanics(t *testing.T) {
+	var logs bytes.Buffer
+	srv := New(nil, WithLogger(slog.New(failingHandler{slog.NewTextHandler(&logs, nil)})))
+	srv.AddGET("/", func(w http.ResponseWriter, r *http.Request) {})
+
+	w := httptest.NewRecorder()
+	srv.Router().ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", w.Code)
+	}
+	if !strings.Contains(logs.String(), "error=\"log failure\"") {
+		t.Errorf("Expected the panic logged, got %q", logs.String())
+	}
+}
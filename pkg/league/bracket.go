@@ -0,0 +1,156 @@
+package league
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// Round names used by NewBracket
+const (
+	RoundPlayoff      = "Knockout phase play-offs"
+	RoundOf16         = "Round of 16"
+	RoundQuarterFinal = "Quarter-finals"
+	RoundSemiFinal    = "Semi-finals"
+	RoundFinal        = "Final"
+)
+
+// bracketTeams is the number of league phase teams the knockout phase needs:
+// places 1-8 go straight to the round of 16 and places 9-24 to the play-offs
+const bracketTeams = 24
+
+// Slot is one side of a knockout tie: either a team known from the league
+// phase or the winner of an earlier tie
+type Slot struct {
+	Team string `json:"team,omitempty"`
+	// Position is the team's league phase position
+	Position int `json:"position,omitempty"`
+	// Winner is the ID of the tie whose winner fills the slot
+	Winner string `json:"winner,omitempty"`
+}
+
+func (s Slot) String() string {
+	if s.Winner != "" {
+		return "winner " + s.Winner
+	}
+	return fmt.Sprintf("%s (%d)", s.Team, s.Position)
+}
+
+// Tie is a knockout pairing. In the play-offs and the round of 16 Top is the
+// seeded team, which plays the second leg at home.
+type Tie struct {
+	ID     string `json:"id"`
+	Top    Slot   `json:"top"`
+	Bottom Slot   `json:"bottom"`
+}
+
+func (t Tie) String() string {
+	return fmt.Sprintf("%s: %s v %s", t.ID, t.Top, t.Bottom)
+}
+
+// Round is a knockout round, its ties in bracket order
+type Round struct {
+	Name string `json:"name"`
+	Ties []Tie  `json:"ties"`
+}
+
+// Bracket is the knockout phase following the league phase
+type Bracket struct {
+	Seed   int64   `json:"seed"`
+	Rounds []Round `json:"rounds"`
+}
+
+// NewBracket draws the knockout phase from the final league phase standings.
+//
+// Places 9-16 are seeded in the play-offs and drawn in pairs against places
+// 17-24: 9 and 10 against 23 and 24, 11 and 12 against 21 and 22, and so on.
+// Places 1-8 are drawn in the same way against the play-off winners: 1 and 2
+// against the ties of 15 and 16, down to 7 and 8 against the ties of 9 and
+// 10. The bracket is fixed from then on. Teams 1 and 2 are placed in
+// opposite halves, as are the teams of every other pair, so they can only
+// meet in the final.
+func NewBracket(s *Standings, seed int64) (*Bracket, error) {
+	if len(s.Rows) < bracketTeams {
+		return nil, fmt.Errorf("%w: %d teams, the knockout phase needs %d", ErrBracketSize, len(s.Rows), bracketTeams)
+	}
+
+	r := rand.New(rand.NewSource(seed))
+	slot := func(position int) Slot {
+		return Slot{Team: s.Rows[position-1].Team, Position: position}
+	}
+
+	// playoffs[k] holds the two ties of the seeded pair 9+2k and 10+2k
+	var playoffs [4][2]Tie
+	playoff := Round{Name: RoundPlayoff}
+	for k := range 4 {
+		unseeded := []int{23 - 2*k, 24 - 2*k}
+		r.Shuffle(2, func(i, j int) { unseeded[i], unseeded[j] = unseeded[j], unseeded[i] })
+
+		for i := range 2 {
+			position := 9 + 2*k + i
+			tie := Tie{
+				ID:     fmt.Sprintf("PO%d", position-8),
+				Top:    slot(position),
+				Bottom: slot(unseeded[i]),
+			}
+			playoffs[k][i] = tie
+			playoff.Ties = append(playoff.Ties, tie)
+		}
+	}
+
+	// roundOf16[half][k] is the tie of the seeded pair 1+2k and 2+2k in the
+	// upper (0) or lower (1) half
+	var roundOf16 [2][4]Tie
+	for k := range 4 {
+		ties := []Tie{playoffs[3-k][0], playoffs[3-k][1]}
+		r.Shuffle(2, func(i, j int) { ties[i], ties[j] = ties[j], ties[i] })
+
+		halves := []int{0, 1}
+		if k > 0 {
+			// 1 and 2 always lead the upper and lower halves
+			r.Shuffle(2, func(i, j int) { halves[i], halves[j] = halves[j], halves[i] })
+		}
+
+		for i := range 2 {
+			roundOf16[halves[i]][k] = Tie{
+				Top:    slot(1 + 2*k + i),
+				Bottom: Slot{Winner: ties[i].ID},
+			}
+		}
+	}
+
+	// every half pairs the ties of 1/2 and 7/8, and of 3/4 and 5/6
+	order := []int{0, 3, 1, 2}
+	rounds := []Round{playoff, {Name: RoundOf16}}
+	for half := range 2 {
+		for _, k := range order {
+			tie := roundOf16[half][k]
+			tie.ID = fmt.Sprintf("R16-%d", len(rounds[1].Ties)+1)
+			rounds[1].Ties = append(rounds[1].Ties, tie)
+		}
+	}
+
+	for _, name := range []string{RoundQuarterFinal, RoundSemiFinal, RoundFinal} {
+		previous := rounds[len(rounds)-1].Ties
+		round := Round{Name: name}
+		for i := 0; i < len(previous); i += 2 {
+			round.Ties = append(round.Ties, Tie{
+				ID:     tieID(name, i/2+1),
+				Top:    Slot{Winner: previous[i].ID},
+				Bottom: Slot{Winner: previous[i+1].ID},
+			})
+		}
+		rounds = append(rounds, round)
+	}
+
+	return &Bracket{Seed: seed, Rounds: rounds}, nil
+}
+
+func tieID(round string, n int) string {
+	switch round {
+	case RoundQuarterFinal:
+		return fmt.Sprintf("QF%d", n)
+	case RoundSemiFinal:
+		return fmt.Sprintf("SF%d", n)
+	}
+	return "F"
+}
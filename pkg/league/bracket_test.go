@@ -0,0 +1,101 @@
+package league
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// testStandings returns a table of n teams named after their position
+func testStandings(n int) *Standings {
+	s := &Standings{}
+	for i := range n {
+		s.Rows = append(s.Rows, Row{Position: i + 1, Team: fmt.Sprintf("T%d", i+1)})
+	}
+	return s
+}
+
+func TestBracket(t *testing.T) {
+	for seed := range int64(20) {
+		b, err := NewBracket(testStandings(36), seed)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		sizes := []int{8, 8, 4, 2, 1}
+		if len(b.Rounds) != len(sizes) {
+			t.Fatalf("Expected %d rounds, got %d", len(sizes), len(b.Rounds))
+		}
+		for i, round := range b.Rounds {
+			if len(round.Ties) != sizes[i] {
+				t.Errorf("Expected %d ties in %s, got %d", sizes[i], round.Name, len(round.Ties))
+			}
+		}
+
+		// play-off pairs: 9/10 against 23/24 and so on
+		playoffs := make(map[string]Tie)
+		for _, tie := range b.Rounds[0].Ties {
+			playoffs[tie.ID] = tie
+			top, bottom := tie.Top.Position, tie.Bottom.Position
+			if top < 9 || top > 16 || (24-bottom)/2 != (top-9)/2 {
+				t.Errorf("Unexpected play-off tie %s", tie)
+			}
+		}
+
+		// round of 16: 1/2 against the ties of 15/16, and so on
+		for _, tie := range b.Rounds[1].Ties {
+			po, ok := playoffs[tie.Bottom.Winner]
+			if !ok {
+				t.Fatalf("Expected %s to face a play-off winner", tie)
+			}
+			if (tie.Top.Position-1)/2 != 3-(po.Top.Position-9)/2 {
+				t.Errorf("Tie %s faces the wrong play-off %s", tie, po)
+			}
+		}
+
+		// 1 and 2 lead opposite halves, as do 3 and 4 and so on
+		upper := make(map[int]bool)
+		for _, tie := range b.Rounds[1].Ties[:4] {
+			upper[tie.Top.Position] = true
+		}
+		if !upper[1] || upper[2] {
+			t.Errorf("Expected 1 in the upper and 2 in the lower half")
+		}
+		for k := 3; k < 8; k += 2 {
+			if upper[k] == upper[k+1] {
+				t.Errorf("Expected %d and %d in opposite halves", k, k+1)
+			}
+		}
+
+		final := b.Rounds[4].Ties[0]
+		if final.ID != "F" || final.Top.Winner != "SF1" || final.Bottom.Winner != "SF2" {
+			t.Errorf("Unexpected final %s", final)
+		}
+	}
+}
+
+func TestBracketErrors(t *testing.T) {
+	if _, err := NewBracket(testStandings(20), 1); !errors.Is(err, ErrBracketSize) {
+		t.Errorf("Expected ErrBracketSize, got %v", err)
+	}
+}
+
+func TestWriteBracket(t *testing.T) {
+	b, err := NewBracket(testStandings(24), 3)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteBracket(&buf, b, "text"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for _, want := range []string{"Seed: 3\n", "Round of 16:\nR16-1: T1 (1) v winner PO", "Final:\nF: winner SF1 v winner SF2\n"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Expected %q in:\n%s", want, buf.String())
+		}
+	}
+}
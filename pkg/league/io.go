@@ -0,0 +1,252 @@
+package league
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Encodings lists the encodings accepted by Write and WriteStandings
+var Encodings = []string{"text", "json", "csv"}
+
+// Write writes the simulation result using the named encoding
+func Write(w io.Writer, r *Result, encoding string) error {
+	switch encoding {
+	case "text":
+		return WriteText(w, r)
+	case "json":
+		return WriteJSON(w, r)
+	case "csv":
+		return WriteCSV(w, r)
+	}
+	return fmt.Errorf("%w: %q", ErrEncoding, encoding)
+}
+
+// MatchEncodings lists the encodings accepted by ReadMatches
+var MatchEncodings = []string{"json", "csv"}
+
+// WriteText writes the result as a table with one row per team
+func WriteText(w io.Writer, r *Result) error {
+	bw := bufio.NewWriter(w)
+
+	width := len("Team")
+	for _, o := range r.Teams {
+		width = max(width, len([]rune(o.Team)))
+	}
+
+	fmt.Fprintf(bw, "Seed: %d\n", r.Seed)
+	fmt.Fprintf(bw, "Runs: %d\n\n", r.Runs)
+
+	fmt.Fprintf(bw, "%-*s %7s %7s %7s %7s %9s\n", width, "Team",
+		fmt.Sprintf("1-%d", r.Top), fmt.Sprintf("%d-%d", r.Top+1, r.Playoff), "Out", "Points", "Position")
+	for _, o := range r.Teams {
+		// pad by runes so accented names line up
+		pad := width - len([]rune(o.Team))
+		fmt.Fprintf(bw, "%s%*s %6.1f%% %6.1f%% %6.1f%% %7.1f %9.1f\n", o.Team, pad, "",
+			100*o.Top, 100*o.Playoff, 100*o.Out, o.Points, o.Position)
+	}
+
+	return bw.Flush()
+}
+
+// WriteJSON writes the result as an indented JSON document
+func WriteJSON(w io.Writer, r *Result) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteCSV writes the result as CSV with a header row and one row per team
+func WriteCSV(w io.Writer, r *Result) error {
+	cw := csv.NewWriter(w)
+
+	cw.Write([]string{"team", "top", "playoff", "out", "points", "position"})
+	for _, o := range r.Teams {
+		cw.Write([]string{
+			o.Team,
+			formatFloat(o.Top),
+			formatFloat(o.Playoff),
+			formatFloat(o.Out),
+			formatFloat(o.Points),
+			formatFloat(o.Position),
+		})
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', 4, 64)
+}
+
+// ReadMatches reads match results in the named encoding. The csv encoding
+// has a header row with the columns home, away, home_goals and away_goals;
+// the json encoding is an array of Match objects.
+func ReadMatches(r io.Reader, encoding string) ([]Match, error) {
+	switch encoding {
+	case "json":
+		var matches []Match
+		if err := json.NewDecoder(r).Decode(&matches); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidResult, err)
+		}
+		return matches, nil
+	case "csv":
+		return readMatchesCSV(r)
+	}
+	return nil, fmt.Errorf("%w: %q", ErrEncoding, encoding)
+}
+
+func readMatchesCSV(r io.Reader) ([]Match, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidResult, err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[strings.TrimSpace(name)] = i
+	}
+	for _, name := range []string{"home", "away", "home_goals", "away_goals"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w: missing %s column", ErrInvalidResult, name)
+		}
+	}
+
+	matches := make([]Match, 0, len(records)-1)
+	for i, record := range records[1:] {
+		line := i + 2
+		field := func(name string) string {
+			return strings.TrimSpace(record[columns[name]])
+		}
+
+		m := Match{Home: field("home"), Away: field("away")}
+		if m.HomeGoals, err = strconv.Atoi(field("home_goals")); err != nil {
+			return nil, fmt.Errorf("%w: line %d: invalid goals %q", ErrInvalidResult, line, field("home_goals"))
+		}
+		if m.AwayGoals, err = strconv.Atoi(field("away_goals")); err != nil {
+			return nil, fmt.Errorf("%w: line %d: invalid goals %q", ErrInvalidResult, line, field("away_goals"))
+		}
+		matches = append(matches, m)
+	}
+
+	return matches, nil
+}
+
+// WriteStandings writes the standings using the named encoding
+func WriteStandings(w io.Writer, s *Standings, encoding string) error {
+	switch encoding {
+	case "text":
+		return WriteStandingsText(w, s)
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(s)
+	case "csv":
+		return WriteStandingsCSV(w, s)
+	}
+	return fmt.Errorf("%w: %q", ErrEncoding, encoding)
+}
+
+// WriteStandingsText writes the table followed by the tiebreak applied to
+// every pair of teams level on points
+func WriteStandingsText(w io.Writer, s *Standings) error {
+	bw := bufio.NewWriter(w)
+
+	width := len("Team")
+	for _, row := range s.Rows {
+		width = max(width, len([]rune(row.Team)))
+	}
+
+	fmt.Fprintf(bw, "%3s %-*s %2s %2s %2s %2s %3s %3s %4s %3s\n", "Pos", width, "Team", "P", "W", "D", "L", "GF", "GA", "GD", "Pts")
+	for _, row := range s.Rows {
+		pad := width - len([]rune(row.Team))
+		fmt.Fprintf(bw, "%3d %s%*s %2d %2d %2d %2d %3d %3d %+4d %3d\n", row.Position, row.Team, pad, "",
+			row.Played, row.Won, row.Drawn, row.Lost, row.GoalsFor, row.GoalsAgainst, row.GoalDifference(), row.Points)
+	}
+
+	first := true
+	for _, row := range s.Rows {
+		if row.Tiebreak == nil {
+			continue
+		}
+		if first {
+			fmt.Fprintln(bw)
+			fmt.Fprintln(bw, "Tiebreaks:")
+			first = false
+		}
+		fmt.Fprintf(bw, "%s: %s\n", row.Team, row.Tiebreak)
+	}
+
+	return bw.Flush()
+}
+
+// WriteStandingsCSV writes the table with a header row and one row per
+// team. The tiebreak columns are empty for teams not level on points with
+// the next team.
+func WriteStandingsCSV(w io.Writer, s *Standings) error {
+	cw := csv.NewWriter(w)
+
+	cw.Write([]string{"position", "team", "played", "won", "drawn", "lost", "goals_for", "goals_against", "points", "tiebreak", "tiebreak_below"})
+	for _, row := range s.Rows {
+		criterion, below := "", ""
+		if row.Tiebreak != nil {
+			criterion, below = row.Tiebreak.Criterion, row.Tiebreak.Below
+		}
+		cw.Write([]string{
+			strconv.Itoa(row.Position),
+			row.Team,
+			strconv.Itoa(row.Played),
+			strconv.Itoa(row.Won),
+			strconv.Itoa(row.Drawn),
+			strconv.Itoa(row.Lost),
+			strconv.Itoa(row.GoalsFor),
+			strconv.Itoa(row.GoalsAgainst),
+			strconv.Itoa(row.Points),
+			criterion,
+			below,
+		})
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// BracketEncodings lists the encodings accepted by WriteBracket
+var BracketEncodings = []string{"text", "json"}
+
+// WriteBracket writes the knockout bracket using the named encoding
+func WriteBracket(w io.Writer, b *Bracket, encoding string) error {
+	switch encoding {
+	case "text":
+		return WriteBracketText(w, b)
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(b)
+	}
+	return fmt.Errorf("%w: %q", ErrEncoding, encoding)
+}
+
+// WriteBracketText writes the seed followed by the ties of every round
+func WriteBracketText(w io.Writer, b *Bracket) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "Seed: %d\n", b.Seed)
+	for _, round := range b.Rounds {
+		fmt.Fprintln(bw)
+		fmt.Fprintf(bw, "%s:\n", round.Name)
+		for _, tie := range round.Ties {
+			fmt.Fprintln(bw, tie)
+		}
+	}
+
+	return bw.Flush()
+}
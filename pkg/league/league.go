@@ -0,0 +1,26 @@
+// Package league plays out the league phase of a draw: it ranks teams from
+// match results, and simulates the fixtures with a team strength model to
+// estimate where every club is likely to finish.
+package league
+
+import "errors"
+
+var (
+	// ErrUnknownTeam is returned when a fixture names a team that is not in
+	// any pot of the draw
+	ErrUnknownTeam = errors.New("league: unknown team")
+	// ErrNoFixtures is returned when a draw has no fixtures to play
+	ErrNoFixtures = errors.New("league: draw has no fixtures")
+	// ErrUnknownFixture is returned for a result of a match that is not a
+	// fixture of the draw
+	ErrUnknownFixture = errors.New("league: result for a match not in the draw")
+	// ErrDuplicateResult is returned when a fixture has more than one result
+	ErrDuplicateResult = errors.New("league: duplicate result")
+	// ErrInvalidResult is returned for malformed match results
+	ErrInvalidResult = errors.New("league: invalid result")
+	// ErrBracketSize is returned when the standings have too few teams for
+	// the knockout phase
+	ErrBracketSize = errors.New("league: too few teams for the knockout phase")
+	// ErrEncoding is returned for unknown output encodings
+	ErrEncoding = errors.New("league: unsupported encoding")
+)
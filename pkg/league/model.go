@@ -0,0 +1,71 @@
+package league
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/patraden/code-with-kids/pkg/draw"
+)
+
+// Model predicts the score of a match. Models are shared by the simulation
+// goroutines and must be safe for concurrent use.
+type Model interface {
+	// Score returns the goals scored by the home and the away team
+	Score(home, away draw.Team, r *rand.Rand) (int, int)
+}
+
+// Poisson draws the goals of each side from a Poisson distribution. Evenly
+// matched teams score Goals on average; every 100 rating points of
+// difference moves the stronger side's mean up, and the weaker side's
+// down, by about 15%.
+type Poisson struct {
+	// Ratings sets the Elo style rating of teams by name. Other teams are
+	// rated from their coefficient by Rating.
+	Ratings map[string]float64
+	// Goals is the mean number of goals scored by evenly matched teams
+	Goals float64
+	// HomeAdvantage is added to the home team's rating
+	HomeAdvantage float64
+}
+
+// DefaultPoisson returns a Poisson model with typical European scoring
+// rates and home advantage
+func DefaultPoisson() *Poisson {
+	return &Poisson{
+		Goals:         1.35,
+		HomeAdvantage: 60,
+	}
+}
+
+// Rating derives an Elo style rating from the team's coefficient: 1500 for
+// an unranked club plus 4 points per coefficient point
+func Rating(team draw.Team) float64 {
+	return 1500 + 4*team.Coefficient
+}
+
+func (m *Poisson) rating(team draw.Team) float64 {
+	if rating, ok := m.Ratings[team.Name]; ok {
+		return rating
+	}
+	return Rating(team)
+}
+
+// Score implements Model
+func (m *Poisson) Score(home, away draw.Team, r *rand.Rand) (int, int) {
+	diff := m.rating(home) + m.HomeAdvantage - m.rating(away)
+	edge := math.Pow(10, diff/1600)
+
+	return poisson(m.Goals*edge, r), poisson(m.Goals/edge, r)
+}
+
+// poisson samples a Poisson distributed number with mean lambda using
+// Knuth's multiplication method, which is fast for football scores
+func poisson(lambda float64, r *rand.Rand) int {
+	limit := math.Exp(-lambda)
+	k, p := 0, r.Float64()
+	for p > limit {
+		k++
+		p *= r.Float64()
+	}
+	return k
+}
@@ -0,0 +1,341 @@
+package league
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/patraden/code-with-kids/pkg/draw"
+)
+
+const (
+	// DefaultRuns is the number of league phases simulated when unset
+	DefaultRuns = 10000
+	// DefaultTop is the last position qualifying directly for the round of 16
+	DefaultTop = 8
+	// DefaultPlayoff is the last position qualifying for the knockout play-off
+	DefaultPlayoff = 24
+
+	// chunkSize is the number of runs sharing a random source. Chunks are
+	// seeded by their index, so results do not depend on the worker count.
+	chunkSize = 100
+)
+
+// Config holds simulation configuration
+type Config struct {
+	Seed int64
+	// Runs is the number of simulated league phases, DefaultRuns when zero
+	Runs int
+	// Workers is the number of simulation goroutines, GOMAXPROCS when zero
+	Workers int
+	// Model predicts match scores, DefaultPoisson when nil
+	Model Model
+	// Top is the last position qualifying directly for the round of 16,
+	// DefaultTop when zero
+	Top int
+	// Playoff is the last position qualifying for the knockout play-off,
+	// DefaultPlayoff when zero
+	Playoff int
+}
+
+// DefaultConfig returns a configuration seeded from the current time
+func DefaultConfig() *Config {
+	return &Config{
+		Seed:    time.Now().UnixNano(),
+		Runs:    DefaultRuns,
+		Workers: runtime.GOMAXPROCS(0),
+		Model:   DefaultPoisson(),
+		Top:     DefaultTop,
+		Playoff: DefaultPlayoff,
+	}
+}
+
+// Simulator plays the fixtures of a draw many times over
+type Simulator struct {
+	config *Config
+	model  Model
+}
+
+// NewSimulator creates a new simulator with the given configuration
+func NewSimulator(config *Config) *Simulator {
+	if config == nil {
+		config = DefaultConfig()
+	}
+
+	model := config.Model
+	if model == nil {
+		model = DefaultPoisson()
+	}
+
+	return &Simulator{
+		config: config,
+		model:  model,
+	}
+}
+
+// Outcome holds the finishing chances of a single team
+type Outcome struct {
+	Team string `json:"team"`
+	// Top is the probability of finishing in the direct qualification places
+	Top float64 `json:"top"`
+	// Playoff is the probability of finishing in the play-off places
+	Playoff float64 `json:"playoff"`
+	// Out is the probability of being eliminated
+	Out float64 `json:"out"`
+	// Points is the mean number of points
+	Points float64 `json:"points"`
+	// Position is the mean finishing position, 1 being first
+	Position float64 `json:"position"`
+}
+
+// Result holds the outcome of every team, best mean position first
+type Result struct {
+	Seed    int64     `json:"seed"`
+	Runs    int       `json:"runs"`
+	Top     int       `json:"top"`
+	Playoff int       `json:"playoff"`
+	Teams   []Outcome `json:"teams"`
+}
+
+// chunkSeed derives the seed of a chunk with a splitmix64 step, so nearby
+// simulation seeds do not share the random streams of their chunks
+func chunkSeed(seed int64, chunk int) int64 {
+	z := uint64(seed) + uint64(chunk+1)*0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return int64(z ^ (z >> 31))
+}
+
+// Run simulates the league phase of the draw. Runs are split into chunks
+// played in parallel; the result only depends on the seed and the number
+// of runs.
+func (s *Simulator) Run(d *draw.Draw) (*Result, error) {
+	league, err := newLeague(d)
+	if err != nil {
+		return nil, err
+	}
+
+	runs := s.config.Runs
+	if runs <= 0 {
+		runs = DefaultRuns
+	}
+	workers := s.config.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	chunks := make(chan int)
+	tallies := make([]*tally, workers)
+	var wg sync.WaitGroup
+	for w := range tallies {
+		tallies[w] = newTally(len(league.teams))
+		wg.Add(1)
+		go func(t *tally) {
+			defer wg.Done()
+			table := newTable(len(league.teams))
+			for chunk := range chunks {
+				r := rand.New(rand.NewSource(chunkSeed(s.config.Seed, chunk)))
+				for range min(chunkSize, runs-chunk*chunkSize) {
+					league.play(s.model, table, r)
+					t.add(table)
+				}
+			}
+		}(tallies[w])
+	}
+
+	for chunk := 0; chunk*chunkSize < runs; chunk++ {
+		chunks <- chunk
+	}
+	close(chunks)
+	wg.Wait()
+
+	total := newTally(len(league.teams))
+	for _, t := range tallies {
+		total.merge(t)
+	}
+
+	return s.result(league, total, runs), nil
+}
+
+func (s *Simulator) result(league *league, total *tally, runs int) *Result {
+	n := len(league.teams)
+	top := zone(s.config.Top, DefaultTop, n)
+	playoff := zone(s.config.Playoff, DefaultPlayoff, n)
+
+	result := &Result{
+		Seed:    s.config.Seed,
+		Runs:    runs,
+		Top:     top,
+		Playoff: max(top, playoff),
+		Teams:   make([]Outcome, n),
+	}
+
+	for t, team := range league.teams {
+		o := Outcome{Team: team.Name}
+		positions := 0
+		for pos, count := range total.positions[t] {
+			share := float64(count) / float64(runs)
+			switch {
+			case pos < result.Top:
+				o.Top += share
+			case pos < result.Playoff:
+				o.Playoff += share
+			default:
+				o.Out += share
+			}
+			positions += (pos + 1) * count
+		}
+		o.Points = float64(total.points[t]) / float64(runs)
+		o.Position = float64(positions) / float64(runs)
+		result.Teams[t] = o
+	}
+
+	sort.SliceStable(result.Teams, func(i, j int) bool {
+		return result.Teams[i].Position < result.Teams[j].Position
+	})
+
+	return result
+}
+
+// zone returns the configured last position of a zone, clamped to the
+// number of teams
+func zone(configured, fallback, teams int) int {
+	if configured <= 0 {
+		configured = fallback
+	}
+	return min(configured, teams)
+}
+
+// league holds the teams and fixtures of a draw by team index
+type league struct {
+	teams    []draw.Team
+	fixtures [][2]int
+}
+
+func newLeague(d *draw.Draw) (*league, error) {
+	if len(d.Fixtures) == 0 {
+		return nil, ErrNoFixtures
+	}
+
+	l := &league{}
+	index := make(map[string]int)
+	for _, pot := range d.Pots {
+		for _, team := range pot.Teams {
+			index[team.Name] = len(l.teams)
+			l.teams = append(l.teams, team)
+		}
+	}
+
+	for _, f := range d.Fixtures {
+		home, ok := index[f.Home]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownTeam, f.Home)
+		}
+		away, ok := index[f.Away]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownTeam, f.Away)
+		}
+		l.fixtures = append(l.fixtures, [2]int{home, away})
+	}
+
+	return l, nil
+}
+
+// table holds the points and goals of every team in a single run
+type table struct {
+	points  []int
+	scored  []int
+	against []int
+	// lots breaks ties left after points and goals
+	lots  []float64
+	order []int
+}
+
+func newTable(n int) *table {
+	return &table{
+		points:  make([]int, n),
+		scored:  make([]int, n),
+		against: make([]int, n),
+		lots:    make([]float64, n),
+		order:   make([]int, n),
+	}
+}
+
+// play simulates every fixture once and ranks the teams by points, goal
+// difference and goals scored, drawing lots for any remaining tie
+func (l *league) play(model Model, t *table, r *rand.Rand) {
+	for i := range t.points {
+		t.points[i], t.scored[i], t.against[i] = 0, 0, 0
+		t.lots[i] = r.Float64()
+		t.order[i] = i
+	}
+
+	for _, f := range l.fixtures {
+		h, a := f[0], f[1]
+		hg, ag := model.Score(l.teams[h], l.teams[a], r)
+		t.scored[h] += hg
+		t.against[h] += ag
+		t.scored[a] += ag
+		t.against[a] += hg
+
+		switch {
+		case hg > ag:
+			t.points[h] += 3
+		case hg < ag:
+			t.points[a] += 3
+		default:
+			t.points[h]++
+			t.points[a]++
+		}
+	}
+
+	sort.Slice(t.order, func(i, j int) bool {
+		a, b := t.order[i], t.order[j]
+		if t.points[a] != t.points[b] {
+			return t.points[a] > t.points[b]
+		}
+		if da, db := t.scored[a]-t.against[a], t.scored[b]-t.against[b]; da != db {
+			return da > db
+		}
+		if t.scored[a] != t.scored[b] {
+			return t.scored[a] > t.scored[b]
+		}
+		return t.lots[a] < t.lots[b]
+	})
+}
+
+// tally counts finishing positions and points over many runs
+type tally struct {
+	positions [][]int // positions[t][p] counts how often team t finished p+1
+	points    []int
+}
+
+func newTally(n int) *tally {
+	t := &tally{
+		positions: make([][]int, n),
+		points:    make([]int, n),
+	}
+	for i := range t.positions {
+		t.positions[i] = make([]int, n)
+	}
+	return t
+}
+
+func (t *tally) add(table *table) {
+	for pos, team := range table.order {
+		t.positions[team][pos]++
+		t.points[team] += table.points[team]
+	}
+}
+
+func (t *tally) merge(other *tally) {
+	for team := range t.positions {
+		for pos, count := range other.positions[team] {
+			t.positions[team][pos] += count
+		}
+		t.points[team] += other.points[team]
+	}
+}
@@ -0,0 +1,128 @@
+package league
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/patraden/code-with-kids/pkg/draw"
+)
+
+func testDraw(t *testing.T) *draw.Draw {
+	t.Helper()
+
+	teams, err := draw.ReadTeamsFile("../../example/teams.txt")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	d, err := draw.NewEngine(&draw.Config{Seed: 1}).Run(teams)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return d
+}
+
+func TestSimulate(t *testing.T) {
+	d := testDraw(t)
+
+	result, err := NewSimulator(&Config{Seed: 1, Runs: 2000}).Run(d)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(result.Teams) != 36 {
+		t.Fatalf("Expected 36 outcomes, got %d", len(result.Teams))
+	}
+	if result.Top != 8 || result.Playoff != 24 {
+		t.Errorf("Expected zones 8 and 24, got %d and %d", result.Top, result.Playoff)
+	}
+
+	var top, playoff float64
+	for i, o := range result.Teams {
+		if sum := o.Top + o.Playoff + o.Out; math.Abs(sum-1) > 1e-9 {
+			t.Errorf("Expected probabilities of %s to sum to 1, got %f", o.Team, sum)
+		}
+		if i > 0 && o.Position < result.Teams[i-1].Position {
+			t.Errorf("Expected outcomes ordered by mean position")
+		}
+		top += o.Top
+		playoff += o.Playoff
+	}
+
+	// every run fills 8 direct and 16 play-off places
+	if math.Abs(top-8) > 1e-9 || math.Abs(playoff-16) > 1e-9 {
+		t.Errorf("Expected 8 top and 16 play-off places, got %f and %f", top, playoff)
+	}
+
+	// stronger clubs should finish higher on average
+	first, last := result.Teams[0].Team, result.Teams[len(result.Teams)-1].Team
+	if first == "Sibir Novosibirsk" || last == "Manchester City" {
+		t.Errorf("Expected the ratings to order the table, got %s first and %s last", first, last)
+	}
+}
+
+func TestSimulateDeterministic(t *testing.T) {
+	d := testDraw(t)
+
+	a, err := NewSimulator(&Config{Seed: 5, Runs: 500, Workers: 1}).Run(d)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	b, err := NewSimulator(&Config{Seed: 5, Runs: 500, Workers: 7}).Run(d)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !reflect.DeepEqual(a, b) {
+		t.Error("Expected the result not to depend on the number of workers")
+	}
+}
+
+func TestChunkSeed(t *testing.T) {
+	seen := map[int64]string{}
+	for seed := int64(0); seed < 10; seed++ {
+		for chunk := range 100 {
+			key := fmt.Sprintf("seed %d chunk %d", seed, chunk)
+			if other, ok := seen[chunkSeed(seed, chunk)]; ok {
+				t.Fatalf("Expected distinct chunk seeds, %s and %s are equal", key, other)
+			}
+			seen[chunkSeed(seed, chunk)] = key
+		}
+	}
+}
+
+func TestSimulateErrors(t *testing.T) {
+	if _, err := NewSimulator(nil).Run(&draw.Draw{}); !errors.Is(err, ErrNoFixtures) {
+		t.Errorf("Expected ErrNoFixtures, got %v", err)
+	}
+
+	d := &draw.Draw{Fixtures: []draw.Fixture{{Home: "A", Away: "B"}}}
+	if _, err := NewSimulator(nil).Run(d); !errors.Is(err, ErrUnknownTeam) {
+		t.Errorf("Expected ErrUnknownTeam, got %v", err)
+	}
+}
+
+func TestPoissonRatings(t *testing.T) {
+	model := DefaultPoisson()
+	model.Ratings = map[string]float64{"Strong": 2200, "Weak": 1400}
+
+	d := &draw.Draw{
+		Pots: []draw.Pot{{Name: "A", Teams: []draw.Team{{Name: "Strong"}, {Name: "Weak"}}}},
+		Fixtures: []draw.Fixture{
+			{Home: "Strong", Away: "Weak"},
+			{Home: "Weak", Away: "Strong"},
+		},
+	}
+
+	result, err := NewSimulator(&Config{Seed: 2, Runs: 1000, Model: model, Top: 1}).Run(d)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result.Teams[0].Team != "Strong" || result.Teams[0].Top < 0.8 {
+		t.Errorf("Expected Strong to top the table most of the time, got %+v", result.Teams)
+	}
+}
@@ -0,0 +1,244 @@
+package league
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/patraden/code-with-kids/pkg/draw"
+)
+
+// Match is the final score of a played fixture
+type Match struct {
+	Home      string `json:"home"`
+	Away      string `json:"away"`
+	HomeGoals int    `json:"home_goals"`
+	AwayGoals int    `json:"away_goals"`
+}
+
+func (m Match) String() string {
+	return fmt.Sprintf("%s %d-%d %s", m.Home, m.HomeGoals, m.AwayGoals, m.Away)
+}
+
+// Row is the line of a single team in the standings
+type Row struct {
+	Position     int     `json:"position"`
+	Team         string  `json:"team"`
+	Played       int     `json:"played"`
+	Won          int     `json:"won"`
+	Drawn        int     `json:"drawn"`
+	Lost         int     `json:"lost"`
+	GoalsFor     int     `json:"goals_for"`
+	GoalsAgainst int     `json:"goals_against"`
+	Points       int     `json:"points"`
+	AwayGoals    int     `json:"away_goals"`
+	AwayWins     int     `json:"away_wins"`
+	Coefficient  float64 `json:"coefficient"`
+	// OpponentPoints, OpponentGoalDifference and OpponentGoals add up the
+	// records of every opponent played so far
+	OpponentPoints         int `json:"opponent_points"`
+	OpponentGoalDifference int `json:"opponent_goal_difference"`
+	OpponentGoals          int `json:"opponent_goals"`
+	// Tiebreak explains how the team was ranked above the next team when
+	// both have the same points
+	Tiebreak *Tiebreak `json:"tiebreak,omitempty"`
+}
+
+// GoalDifference returns goals scored minus goals conceded
+func (r *Row) GoalDifference() int {
+	return r.GoalsFor - r.GoalsAgainst
+}
+
+// Tiebreak records the criterion that separated two teams level on points
+type Tiebreak struct {
+	// Below is the team ranked directly below
+	Below     string `json:"below"`
+	Criterion string `json:"criterion"`
+	// Value and BelowValue are what the two teams scored on the criterion
+	Value      float64 `json:"value"`
+	BelowValue float64 `json:"below_value"`
+}
+
+func (t *Tiebreak) String() string {
+	if t.Criterion == CriterionName {
+		return fmt.Sprintf("level with %s on every criterion, ordered by name in place of drawing lots", t.Below)
+	}
+	return fmt.Sprintf("ahead of %s on %s (%g vs %g)", t.Below, t.Criterion, t.Value, t.BelowValue)
+}
+
+// Tiebreak criteria in the order they are applied after points. Fair play
+// points are not tracked, so disciplinary records are skipped.
+const (
+	CriterionGoalDifference         = "goal difference"
+	CriterionGoalsFor               = "goals scored"
+	CriterionAwayGoals              = "away goals scored"
+	CriterionWins                   = "wins"
+	CriterionAwayWins               = "away wins"
+	CriterionOpponentPoints         = "opponents' points"
+	CriterionOpponentGoalDifference = "opponents' goal difference"
+	CriterionOpponentGoals          = "opponents' goals scored"
+	CriterionCoefficient            = "club coefficient"
+	// CriterionName stands in for drawing lots so the table is deterministic
+	CriterionName = "name"
+)
+
+// criterion ranks rows by a value, higher first
+type criterion struct {
+	name  string
+	value func(r *Row) float64
+}
+
+var criteria = []criterion{
+	{CriterionGoalDifference, func(r *Row) float64 { return float64(r.GoalDifference()) }},
+	{CriterionGoalsFor, func(r *Row) float64 { return float64(r.GoalsFor) }},
+	{CriterionAwayGoals, func(r *Row) float64 { return float64(r.AwayGoals) }},
+	{CriterionWins, func(r *Row) float64 { return float64(r.Won) }},
+	{CriterionAwayWins, func(r *Row) float64 { return float64(r.AwayWins) }},
+	{CriterionOpponentPoints, func(r *Row) float64 { return float64(r.OpponentPoints) }},
+	{CriterionOpponentGoalDifference, func(r *Row) float64 { return float64(r.OpponentGoalDifference) }},
+	{CriterionOpponentGoals, func(r *Row) float64 { return float64(r.OpponentGoals) }},
+	{CriterionCoefficient, func(r *Row) float64 { return r.Coefficient }},
+}
+
+// Standings is the league phase table
+type Standings struct {
+	Rows []Row `json:"rows"`
+}
+
+// NewStandings builds the table of the draw from the played matches. The
+// fixtures must be between teams of the pots. Every match must be a fixture
+// of the draw and may be reported once; fixtures without a result are not
+// played yet. Teams level on points are separated by goal difference, goals
+// scored, away goals scored, wins, away wins, and the points, goal
+// difference and goals scored of their opponents, then by club coefficient.
+func NewStandings(d *draw.Draw, matches []Match) (*Standings, error) {
+	var rows []Row
+	index := make(map[string]int)
+	for _, pot := range d.Pots {
+		for _, team := range pot.Teams {
+			index[team.Name] = len(rows)
+			rows = append(rows, Row{Team: team.Name, Coefficient: team.Coefficient})
+		}
+	}
+
+	fixtures := make(map[[2]string]bool, len(d.Fixtures))
+	for _, f := range d.Fixtures {
+		for _, name := range []string{f.Home, f.Away} {
+			if _, ok := index[name]; !ok {
+				return nil, fmt.Errorf("%w: %q", ErrUnknownTeam, name)
+			}
+		}
+		fixtures[[2]string{f.Home, f.Away}] = true
+	}
+
+	played := make(map[[2]string]bool, len(matches))
+	opponents := make([][]int, len(rows))
+	for _, m := range matches {
+		key := [2]string{m.Home, m.Away}
+		if !fixtures[key] {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownFixture, m)
+		}
+		if played[key] {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateResult, m)
+		}
+		if m.HomeGoals < 0 || m.AwayGoals < 0 {
+			return nil, fmt.Errorf("%w: negative score in %s", ErrInvalidResult, m)
+		}
+		played[key] = true
+
+		h, a := index[m.Home], index[m.Away]
+		home, away := &rows[h], &rows[a]
+		opponents[h] = append(opponents[h], a)
+		opponents[a] = append(opponents[a], h)
+
+		home.record(m.HomeGoals, m.AwayGoals)
+		away.record(m.AwayGoals, m.HomeGoals)
+		away.AwayGoals += m.AwayGoals
+		if m.AwayGoals > m.HomeGoals {
+			away.AwayWins++
+		}
+	}
+
+	for t := range rows {
+		for _, o := range opponents[t] {
+			rows[t].OpponentPoints += rows[o].Points
+			rows[t].OpponentGoalDifference += rows[o].GoalDifference()
+			rows[t].OpponentGoals += rows[o].GoalsFor
+		}
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		return compare(&rows[i], &rows[j]) < 0
+	})
+
+	for i := range rows {
+		rows[i].Position = i + 1
+		if i+1 < len(rows) && rows[i].Points == rows[i+1].Points {
+			rows[i].Tiebreak = tiebreak(&rows[i], &rows[i+1])
+		}
+	}
+
+	return &Standings{Rows: rows}, nil
+}
+
+// record adds a match scored for and against the team
+func (r *Row) record(scored, conceded int) {
+	r.Played++
+	r.GoalsFor += scored
+	r.GoalsAgainst += conceded
+
+	switch {
+	case scored > conceded:
+		r.Won++
+		r.Points += 3
+	case scored == conceded:
+		r.Drawn++
+		r.Points++
+	default:
+		r.Lost++
+	}
+}
+
+// compare returns a negative number when a ranks above b
+func compare(a, b *Row) int {
+	if a.Points != b.Points {
+		return b.Points - a.Points
+	}
+
+	for _, c := range criteria {
+		if va, vb := c.value(a), c.value(b); va != vb {
+			if va > vb {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	switch {
+	case a.Team < b.Team:
+		return -1
+	case a.Team > b.Team:
+		return 1
+	}
+	return 0
+}
+
+// tiebreak explains how a, level on points with b, was ranked above it
+func tiebreak(a, b *Row) *Tiebreak {
+	for _, c := range criteria {
+		if va, vb := c.value(a), c.value(b); va != vb {
+			return &Tiebreak{Below: b.Team, Criterion: c.name, Value: va, BelowValue: vb}
+		}
+	}
+
+	return &Tiebreak{Below: b.Team, Criterion: CriterionName}
+}
+
+// Row returns the row of the named team, or nil when it is not in the table
+func (s *Standings) Row(team string) *Row {
+	for i := range s.Rows {
+		if s.Rows[i].Team == team {
+			return &s.Rows[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package league
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/patraden/code-with-kids/pkg/draw"
+)
+
+// pairDraw returns a draw where two teams meet home and away
+func pairDraw(x, y draw.Team) *draw.Draw {
+	return &draw.Draw{
+		Pots: []draw.Pot{{Name: "A", Teams: []draw.Team{x, y}}},
+		Fixtures: []draw.Fixture{
+			{Home: x.Name, Away: y.Name},
+			{Home: y.Name, Away: x.Name},
+		},
+	}
+}
+
+func TestStandings(t *testing.T) {
+	d := &draw.Draw{
+		Pots: []draw.Pot{
+			{Name: "A", Teams: []draw.Team{{Name: "A"}, {Name: "B"}}},
+			{Name: "B", Teams: []draw.Team{{Name: "C"}, {Name: "D"}}},
+		},
+		Fixtures: []draw.Fixture{
+			{Home: "A", Away: "B"},
+			{Home: "C", Away: "D"},
+			{Home: "B", Away: "C"},
+			{Home: "D", Away: "A"},
+		},
+	}
+
+	s, err := NewStandings(d, []Match{
+		{Home: "A", Away: "B", HomeGoals: 2, AwayGoals: 0},
+		{Home: "C", Away: "D", HomeGoals: 1, AwayGoals: 0},
+		{Home: "B", Away: "C", HomeGoals: 3, AwayGoals: 1},
+		{Home: "D", Away: "A", HomeGoals: 0, AwayGoals: 0},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var order []string
+	for _, row := range s.Rows {
+		order = append(order, row.Team)
+	}
+	if got := strings.Join(order, ","); got != "A,B,C,D" {
+		t.Fatalf("Expected order A,B,C,D, got %s", got)
+	}
+
+	a := s.Row("A")
+	if a.Points != 4 || a.Won != 1 || a.Drawn != 1 || a.AwayGoals != 0 || a.OpponentPoints != 4 {
+		t.Errorf("Unexpected row for A: %+v", a)
+	}
+
+	b := s.Row("B")
+	if b.Tiebreak == nil || b.Tiebreak.Criterion != CriterionGoalDifference || b.Tiebreak.Below != "C" {
+		t.Fatalf("Expected B above C on goal difference, got %+v", b.Tiebreak)
+	}
+	if want := "ahead of C on goal difference (0 vs -1)"; b.Tiebreak.String() != want {
+		t.Errorf("Expected %q, got %q", want, b.Tiebreak.String())
+	}
+	if a.Tiebreak != nil {
+		t.Errorf("Expected no tiebreak for A, got %+v", a.Tiebreak)
+	}
+}
+
+func TestStandingsTiebreakers(t *testing.T) {
+	tests := []struct {
+		name      string
+		x, y      draw.Team
+		first     Match
+		second    Match
+		top       string
+		criterion string
+	}{
+		{
+			name:      "away goals",
+			x:         draw.Team{Name: "X"},
+			y:         draw.Team{Name: "Y"},
+			first:     Match{Home: "X", Away: "Y", HomeGoals: 3, AwayGoals: 2},
+			second:    Match{Home: "Y", Away: "X", HomeGoals: 2, AwayGoals: 1},
+			top:       "Y",
+			criterion: CriterionAwayGoals,
+		},
+		{
+			name:      "coefficient",
+			x:         draw.Team{Name: "X", Coefficient: 10},
+			y:         draw.Team{Name: "Y", Coefficient: 20},
+			first:     Match{Home: "X", Away: "Y", HomeGoals: 1, AwayGoals: 1},
+			second:    Match{Home: "Y", Away: "X", HomeGoals: 1, AwayGoals: 1},
+			top:       "Y",
+			criterion: CriterionCoefficient,
+		},
+		{
+			name:      "lots",
+			x:         draw.Team{Name: "X"},
+			y:         draw.Team{Name: "Y"},
+			first:     Match{Home: "X", Away: "Y", HomeGoals: 0, AwayGoals: 0},
+			second:    Match{Home: "Y", Away: "X", HomeGoals: 0, AwayGoals: 0},
+			top:       "X",
+			criterion: CriterionName,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewStandings(pairDraw(tt.x, tt.y), []Match{tt.first, tt.second})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			row := s.Rows[0]
+			if row.Team != tt.top {
+				t.Errorf("Expected %s on top, got %s", tt.top, row.Team)
+			}
+			if row.Tiebreak == nil || row.Tiebreak.Criterion != tt.criterion {
+				t.Errorf("Expected tie decided on %s, got %+v", tt.criterion, row.Tiebreak)
+			}
+		})
+	}
+}
+
+func TestStandingsErrors(t *testing.T) {
+	d := pairDraw(draw.Team{Name: "X"}, draw.Team{Name: "Y"})
+
+	played := Match{Home: "X", Away: "Y", HomeGoals: 1}
+	if _, err := NewStandings(d, []Match{played, played}); !errors.Is(err, ErrDuplicateResult) {
+		t.Errorf("Expected ErrDuplicateResult, got %v", err)
+	}
+	if _, err := NewStandings(d, []Match{{Home: "X", Away: "Z"}}); !errors.Is(err, ErrUnknownFixture) {
+		t.Errorf("Expected ErrUnknownFixture, got %v", err)
+	}
+	if _, err := NewStandings(d, []Match{{Home: "X", Away: "Y", HomeGoals: -1}}); !errors.Is(err, ErrInvalidResult) {
+		t.Errorf("Expected ErrInvalidResult, got %v", err)
+	}
+
+	// a hand-edited fixture naming a team outside the pots
+	d.Fixtures = append(d.Fixtures, draw.Fixture{Home: "X", Away: "Z"})
+	if _, err := NewStandings(d, []Match{{Home: "X", Away: "Z", HomeGoals: 1}}); !errors.Is(err, ErrUnknownTeam) {
+		t.Errorf("Expected ErrUnknownTeam, got %v", err)
+	}
+}
+
+func TestReadMatches(t *testing.T) {
+	input := "home,away,home_goals,away_goals\nX, Y ,2,1\nY,X,0,0\n"
+	matches, err := ReadMatches(strings.NewReader(input), "csv")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(matches) != 2 || matches[0] != (Match{Home: "X", Away: "Y", HomeGoals: 2, AwayGoals: 1}) {
+		t.Errorf("Unexpected matches %+v", matches)
+	}
+
+	if _, err := ReadMatches(strings.NewReader("home,away,home_goals,away_goals\nX,Y,two,1\n"), "csv"); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Expected line 2 error, got %v", err)
+	}
+
+	s, err := NewStandings(pairDraw(draw.Team{Name: "X"}, draw.Team{Name: "Y"}), matches)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteStandingsText(&buf, s); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(buf.String(), "  1 X     2  1  1  0   2   1   +1   4") || strings.Contains(buf.String(), "Tiebreaks:") {
+		t.Errorf("Unexpected table:\n%s", buf.String())
+	}
+}
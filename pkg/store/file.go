@@ -0,0 +1,231 @@
+package store
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/patraden/code-with-kids/pkg/draw"
+)
+
+// historyFile is the name of the change log kept next to the draws
+const historyFile = "history.jsonl"
+
+// FileStore keeps every draw as a JSON file in a directory, named after its
+// ID, next to an append-only log of changes with one JSON object per line
+type FileStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileStore opens the store in dir, creating the directory if needed
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// Dir returns the directory holding the draws
+func (s *FileStore) Dir() string {
+	return s.dir
+}
+
+// Create stores the draw under a new ID
+func (s *FileStore) Create(d *draw.Draw) (*Record, error) {
+	t := now()
+	r := &Record{ID: newID(), CreatedAt: t, UpdatedAt: t, Draw: d}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.write(r); err != nil {
+		return nil, err
+	}
+	if err := s.log(Change{Time: t, ID: r.ID, Action: ActionCreated}); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Get returns the draw stored under the ID
+func (s *FileStore) Get(id string) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.read(id)
+}
+
+// Update replaces the draw and results of a stored record
+func (s *FileStore) Update(r *Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, err := s.read(r.ID)
+	if err != nil {
+		return err
+	}
+
+	updated := r.clone()
+	updated.CreatedAt = old.CreatedAt
+	updated.UpdatedAt = now()
+	if err := s.write(updated); err != nil {
+		return err
+	}
+	if err := s.log(Change{Time: updated.UpdatedAt, ID: r.ID, Action: ActionUpdated}); err != nil {
+		return err
+	}
+
+	r.CreatedAt, r.UpdatedAt = updated.CreatedAt, updated.UpdatedAt
+	return nil
+}
+
+// List returns every stored draw, oldest first
+func (s *FileStore) List() ([]*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []*Record
+	for _, e := range entries {
+		id, ok := strings.CutSuffix(e.Name(), ".json")
+		if !ok || !validID(id) {
+			continue
+		}
+
+		r, err := s.read(id)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, r)
+	}
+
+	sortRecords(records)
+	return records, nil
+}
+
+// Delete removes the draw stored under the ID
+func (s *FileStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !validID(id) {
+		return fmt.Errorf("%w: %q", ErrNotFound, id)
+	}
+	if err := os.Remove(s.path(id)); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("%w: %q", ErrNotFound, id)
+		}
+		return err
+	}
+
+	return s.log(Change{Time: now(), ID: id, Action: ActionDeleted})
+}
+
+// History returns the changes to the draw, or to every draw when the ID is
+// empty, oldest first
+func (s *FileStore) History(id string) ([]Change, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.Open(filepath.Join(s.dir, historyFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var changes []Change
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var c Change
+		if err := json.Unmarshal(scanner.Bytes(), &c); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", historyFile, line, err)
+		}
+		changes = append(changes, c)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return filter(changes, id), nil
+}
+
+func (s *FileStore) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+func (s *FileStore) read(id string) (*Record, error) {
+	if !validID(id) {
+		return nil, fmt.Errorf("%w: %q", ErrNotFound, id)
+	}
+
+	data, err := os.ReadFile(s.path(id))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %q", ErrNotFound, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var r Record
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("%s: %w", s.path(id), err)
+	}
+	return &r, nil
+}
+
+// write replaces the file of the record through a temporary file, so a
+// crash never leaves a half written draw behind
+func (s *FileStore) write(r *Record) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(s.dir, r.ID+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.path(r.ID))
+}
+
+// log appends the change to the history file
+func (s *FileStore) log(c Change) error {
+	file, err := os.OpenFile(filepath.Join(s.dir, historyFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if err := json.NewEncoder(file).Encode(c); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,111 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/patraden/code-with-kids/pkg/draw"
+)
+
+// MemoryStore keeps draws in memory, for tests and short-lived servers
+type MemoryStore struct {
+	mu      sync.RWMutex
+	records map[string]*Record
+	history []Change
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[string]*Record)}
+}
+
+// Create stores the draw under a new ID
+func (s *MemoryStore) Create(d *draw.Draw) (*Record, error) {
+	t := now()
+	r := &Record{ID: newID(), CreatedAt: t, UpdatedAt: t, Draw: d}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.records[r.ID] = r.clone()
+	s.history = append(s.history, Change{Time: t, ID: r.ID, Action: ActionCreated})
+	return r, nil
+}
+
+// Get returns the draw stored under the ID
+func (s *MemoryStore) Get(id string) (*Record, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	r, ok := s.records[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrNotFound, id)
+	}
+	return r.clone(), nil
+}
+
+// Update replaces the draw and results of a stored record
+func (s *MemoryStore) Update(r *Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, ok := s.records[r.ID]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrNotFound, r.ID)
+	}
+
+	updated := r.clone()
+	updated.CreatedAt = old.CreatedAt
+	updated.UpdatedAt = now()
+	s.records[r.ID] = updated
+	s.history = append(s.history, Change{Time: updated.UpdatedAt, ID: r.ID, Action: ActionUpdated})
+	r.CreatedAt, r.UpdatedAt = updated.CreatedAt, updated.UpdatedAt
+	return nil
+}
+
+// List returns every stored draw, oldest first
+func (s *MemoryStore) List() ([]*Record, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	records := make([]*Record, 0, len(s.records))
+	for _, r := range s.records {
+		records = append(records, r.clone())
+	}
+	sortRecords(records)
+	return records, nil
+}
+
+// Delete removes the draw stored under the ID
+func (s *MemoryStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.records[id]; !ok {
+		return fmt.Errorf("%w: %q", ErrNotFound, id)
+	}
+
+	delete(s.records, id)
+	s.history = append(s.history, Change{Time: now(), ID: id, Action: ActionDeleted})
+	return nil
+}
+
+// History returns the changes to the draw, or to every draw when the ID is
+// empty, oldest first
+func (s *MemoryStore) History(id string) ([]Change, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return filter(s.history, id), nil
+}
+
+// sortRecords orders records by creation time, then by ID
+func sortRecords(records []*Record) {
+	sort.Slice(records, func(i, j int) bool {
+		if !records[i].CreatedAt.Equal(records[j].CreatedAt) {
+			return records[i].CreatedAt.Before(records[j].CreatedAt)
+		}
+		return records[i].ID < records[j].ID
+	})
+}
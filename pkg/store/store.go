@@ -0,0 +1,160 @@
+// Package store keeps draws and the match results reported for them, so
+// they outlive a single run of the CLI or the server.
+package store
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/patraden/code-with-kids/pkg/draw"
+	"github.com/patraden/code-with-kids/pkg/league"
+)
+
+// ErrNotFound is returned when no draw is stored under the ID
+var ErrNotFound = errors.New("store: draw not found")
+
+// Record is a stored draw with the match results reported so far
+type Record struct {
+	ID        string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Draw      *draw.Draw
+	Results   []league.Match
+}
+
+// record is the JSON representation of a Record. The draw is kept in the
+// versioned document written by draw.WriteJSON.
+type record struct {
+	ID        string          `json:"id"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+	Draw      json.RawMessage `json:"draw"`
+	Results   []league.Match  `json:"results,omitempty"`
+}
+
+// MarshalJSON encodes the record with its draw as a draw.WriteJSON document
+func (r *Record) MarshalJSON() ([]byte, error) {
+	var d bytes.Buffer
+	if err := draw.WriteJSON(&d, r.Draw); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(record{
+		ID:        r.ID,
+		CreatedAt: r.CreatedAt,
+		UpdatedAt: r.UpdatedAt,
+		Draw:      d.Bytes(),
+		Results:   r.Results,
+	})
+}
+
+// UnmarshalJSON decodes a record written by MarshalJSON
+func (r *Record) UnmarshalJSON(data []byte) error {
+	var rec record
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return err
+	}
+
+	d, err := draw.ReadJSON(bytes.NewReader(rec.Draw))
+	if err != nil {
+		return err
+	}
+
+	*r = Record{
+		ID:        rec.ID,
+		CreatedAt: rec.CreatedAt,
+		UpdatedAt: rec.UpdatedAt,
+		Draw:      d,
+		Results:   rec.Results,
+	}
+	return nil
+}
+
+// clone copies the record, draw included, so callers cannot change stored
+// records
+func (r *Record) clone() *Record {
+	c := *r
+	c.Draw = r.Draw.Clone()
+	c.Results = append([]league.Match(nil), r.Results...)
+	return &c
+}
+
+// Action is the kind of change recorded in the history
+type Action string
+
+const (
+	ActionCreated Action = "created"
+	ActionUpdated Action = "updated"
+	ActionDeleted Action = "deleted"
+)
+
+// Change is an entry of the store history
+type Change struct {
+	Time   time.Time `json:"time"`
+	ID     string    `json:"id"`
+	Action Action    `json:"action"`
+}
+
+// DrawStore keeps draws by ID
+type DrawStore interface {
+	// Create stores the draw under a new ID
+	Create(d *draw.Draw) (*Record, error)
+	// Get returns the draw stored under the ID
+	Get(id string) (*Record, error)
+	// Update replaces the draw and results of a stored record and sets the
+	// timestamps of r to the stored ones
+	Update(r *Record) error
+	// List returns every stored draw, oldest first
+	List() ([]*Record, error)
+	// Delete removes the draw stored under the ID
+	Delete(id string) error
+	// History returns the changes to the draw, or to every draw when the ID
+	// is empty, oldest first. Deleted draws keep their history.
+	History(id string) ([]Change, error)
+}
+
+var (
+	_ DrawStore = (*MemoryStore)(nil)
+	_ DrawStore = (*FileStore)(nil)
+)
+
+// newID returns a random draw ID of 16 hex digits
+func newID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// validID reports whether the ID could have been made by newID, which keeps
+// IDs safe to use as file names
+func validID(id string) bool {
+	if len(id) != 16 {
+		return false
+	}
+	_, err := hex.DecodeString(id)
+	return err == nil
+}
+
+// filter returns the changes to the draw, or all of them for an empty ID
+func filter(changes []Change, id string) []Change {
+	if id == "" {
+		return append([]Change(nil), changes...)
+	}
+
+	var matched []Change
+	for _, c := range changes {
+		if c.ID == id {
+			matched = append(matched, c)
+		}
+	}
+	return matched
+}
+
+// now returns the current time, rounded so it survives a JSON round trip
+func now() time.Time {
+	return time.Now().UTC().Round(time.Microsecond)
+}
@@ -0,0 +1,158 @@
+package store
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/patraden/code-with-kids/pkg/draw"
+	"github.com/patraden/code-with-kids/pkg/league"
+)
+
+func testDraw(t *testing.T) *draw.Draw {
+	t.Helper()
+
+	teams, err := draw.ReadTeamsFile("../../example/teams.txt")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	d, err := draw.NewEngine(&draw.Config{Seed: 7}).Run(teams)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return d
+}
+
+func TestStores(t *testing.T) {
+	stores := map[string]func(t *testing.T) DrawStore{
+		"memory": func(t *testing.T) DrawStore { return NewMemoryStore() },
+		"file": func(t *testing.T) DrawStore {
+			s, err := NewFileStore(filepath.Join(t.TempDir(), "draws"))
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			return s
+		},
+	}
+
+	for name, open := range stores {
+		t.Run(name, func(t *testing.T) {
+			testStore(t, open(t))
+		})
+	}
+}
+
+func testStore(t *testing.T, s DrawStore) {
+	d := testDraw(t)
+
+	first, err := s.Create(d)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	second, err := s.Create(d)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if first.ID == second.ID || !validID(first.ID) {
+		t.Fatalf("Expected two distinct valid IDs, got %q and %q", first.ID, second.ID)
+	}
+
+	got, err := s.Get(first.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(got.Draw.Fixtures, d.Fixtures) || got.Draw.Seed != d.Seed {
+		t.Error("Expected the stored draw to match the created one")
+	}
+
+	// changing the created or returned draws leaves the stored one alone
+	want := d.Fixtures[0]
+	d.Fixtures[0].Matchday = 9
+	got.Draw.Fixtures[0].Matchday = 9
+	got.Draw.Pots[0].Teams[0].Name = "Changed"
+	if again, _ := s.Get(first.ID); again.Draw.Fixtures[0] != want || again.Draw.Pots[0].Teams[0].Name == "Changed" {
+		t.Error("Expected the stored draw to be unaffected by callers")
+	}
+	d.Fixtures[0].Matchday = want.Matchday
+	got, _ = s.Get(first.ID)
+
+	got.Results = []league.Match{{Home: d.Fixtures[0].Home, Away: d.Fixtures[0].Away, HomeGoals: 1}}
+	if err := s.Update(got); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got.UpdatedAt.Before(got.CreatedAt) || !got.CreatedAt.Equal(first.CreatedAt) {
+		t.Errorf("Expected updated timestamps, got created %v and updated %v", got.CreatedAt, got.UpdatedAt)
+	}
+	updated, _ := s.Get(first.ID)
+	if len(updated.Results) != 1 {
+		t.Errorf("Expected 1 stored result, got %d", len(updated.Results))
+	}
+
+	records, err := s.List()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(records) != 2 || records[0].ID != first.ID || records[1].ID != second.ID {
+		t.Errorf("Expected both draws oldest first, got %d records", len(records))
+	}
+
+	if err := s.Delete(first.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := s.Get(first.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after delete, got %v", err)
+	}
+	if err := s.Delete(first.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound deleting twice, got %v", err)
+	}
+	if err := s.Update(&Record{ID: first.ID, Draw: d}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound updating a deleted draw, got %v", err)
+	}
+	if _, err := s.Get("../history"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an invalid ID, got %v", err)
+	}
+
+	history, err := s.History(first.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var actions []Action
+	for _, c := range history {
+		actions = append(actions, c.Action)
+	}
+	if want := []Action{ActionCreated, ActionUpdated, ActionDeleted}; !reflect.DeepEqual(actions, want) {
+		t.Errorf("Expected history %v, got %v", want, actions)
+	}
+
+	all, _ := s.History("")
+	if len(all) != 4 {
+		t.Errorf("Expected 4 changes in total, got %d", len(all))
+	}
+}
+
+func TestFileStoreReopen(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	r, err := s.Create(testDraw(t))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not a draw"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, _ := NewFileStore(dir)
+	records, err := reopened.List()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(records) != 1 || records[0].ID != r.ID || !records[0].CreatedAt.Equal(r.CreatedAt) {
+		t.Errorf("Expected the draw to survive reopening the store, got %v", records)
+	}
+}